@@ -2,20 +2,30 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"runtime/pprof"
 	"strconv"
+	"strings"
 
+	"github.com/editorconfig/editorconfig-core-go/v2"
 	"github.com/gkampitakis/ciinfo"
 
 	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/baseline"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/cache"
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
 	eccerror "github.com/editorconfig-checker/editorconfig-checker/v3/pkg/error"
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/files"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/newlines"
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/outputformat"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/run"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/source"
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/utils"
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/validation"
 	// x-release-please-end
@@ -34,6 +44,12 @@ var currentConfig *config.Config
 // exitProxy is there to be replaced while running the tests
 var exitProxy = os.Exit
 
+// stdin is there to be replaced while running the tests
+var stdin io.Reader = os.Stdin
+
+// defaultCacheDir is there to be replaced while running the tests
+var defaultCacheDir = cache.DefaultDir
+
 //  loggerInjectionHook is there to be replaced while running the tests
 var loggerInjectionHook = func() {}
 
@@ -77,6 +93,10 @@ func init() {
 	flag.BoolVar(&writeConfigFile, "init", false, "creates an initial configuration")
 	flag.StringVar(&configFilePath, "config", "", "config")
 	flag.StringVar(&cmdlineExclude, "exclude", "", "a regex which files should be excluded from checking - needs to be a valid regular expression. Combine patterns with | (pipe): -exclude \"vendor|testdata\"")
+	flag.Func("exclude-glob", "a gitignore-style glob pattern of files which should be excluded from checking, like vendor/** - can be given multiple times, a pattern starting with ! re-includes files", func(pattern string) error {
+		cmdlineConfig.ExcludeGlobs = append(cmdlineConfig.ExcludeGlobs, pattern)
+		return nil
+	})
 	flag.BoolVar(&cmdlineConfig.IgnoreDefaults, "ignore-defaults", false, "ignore default excludes")
 	flag.BoolVar(&cmdlineConfig.DryRun, "dry-run", false, "show which files would be checked")
 	flag.BoolVar(&cmdlineConfig.ShowVersion, "version", false, "print the version number")
@@ -89,14 +109,56 @@ func init() {
 	flag.BoolVar(&cmdlineConfig.Debug, "debug", false, "print debugging information")
 	flag.BoolFunc("no-color", "disables printing color", enableNoColor)
 	flag.BoolFunc("color", "enables printing color", disableNoColor)
-	flag.BoolVar(&cmdlineConfig.Disable.TrimTrailingWhitespace, "disable-trim-trailing-whitespace", false, "disables the trailing whitespace check")
-	flag.BoolVar(&cmdlineConfig.Disable.EndOfLine, "disable-end-of-line", false, "disables the end-of-line check")
-	flag.BoolVar(&cmdlineConfig.Disable.InsertFinalNewline, "disable-insert-final-newline", false, "disables the final newline check")
-	flag.BoolVar(&cmdlineConfig.Disable.Indentation, "disable-indentation", false, "disables the indentation check")
-	flag.BoolVar(&cmdlineConfig.Disable.IndentSize, "disable-indent-size", false, "disables only the indent-size check")
-	flag.BoolVar(&cmdlineConfig.Disable.MaxLineLength, "disable-max-line-length", false, "disables only the max-line-length check")
+	// every registered rule can be disabled
+	for _, rule := range validation.Rules() {
+		flag.BoolFunc("disable-"+rule.ID, "disables the "+rule.ID+" rule, which checks that "+rule.Description, disableCheck(rule.ID))
+	}
+	flag.BoolFunc("disable-"+config.IndentSizeCheck, "disables only the indent-size check of the indentation rule", disableCheck(config.IndentSizeCheck))
+	flag.Func("rule", "set a rule to off, warning or error, like max-line-length=warning - can be given multiple times", func(value string) error {
+		rule, severity, ok := strings.Cut(value, "=")
+		if !ok {
+			return fmt.Errorf("expected a rule and its severity like max-line-length=warning, got %q", value)
+		}
+		if cmdlineConfig.Rules == nil {
+			cmdlineConfig.Rules = make(map[string]string)
+		}
+		cmdlineConfig.Rules[rule] = severity
+		return nil
+	})
 	flag.StringVar(&cpuprofile, "cpuprofile", "", "write cpu profile to file")
-	flag.BoolVar(&cmdlineConfig.Disable.Charset, "disable-charset", false, "disables only the charset check")
+	flag.StringVar(&cmdlineConfig.Baseline, "baseline", "", "a baseline file whose recorded errors are not reported")
+	flag.StringVar(&cmdlineConfig.BaselineWrite, "baseline-write", "", "record the errors found in a baseline file instead of reporting them")
+	flag.Int64Var(&cmdlineConfig.MaxFileSize, "max-file-size", 0, "skip files larger than the given number of bytes, 0 means no limit")
+	flag.IntVar(&cmdlineConfig.LargeFileLines, "large-file-lines", 0, "check the first given number of lines of files larger than --max-file-size instead of skipping them")
+	flag.BoolVar(&cmdlineConfig.NoGit, "no-git", false, "find the files by walking the directory and reading the .gitignore files instead of running git ls-files")
+	flag.StringVar(&cmdlineConfig.ChangedSince, "changed-since", "", "only check files which were added or modified since the given git ref")
+	flag.StringVar(&cmdlineConfig.MergeBase, "merge-base", "", "only check files which were added or modified since the merge base of HEAD and the given git branch")
+	flag.BoolVar(&cmdlineConfig.Staged, "staged", false, "check the content staged in the git index instead of the working tree, for use in a pre-commit hook")
+	flag.StringVar(&cmdlineConfig.Ref, "ref", "", "check the files of the given git commit, branch or tag with its .editorconfig files, without checking it out")
+	flag.BoolVar(&cmdlineConfig.Stdin, "stdin", false, "check the content read from stdin as the file given by --stdin-filename, like an unsaved buffer of an editor")
+	flag.StringVar(&cmdlineConfig.StdinFilename, "stdin-filename", "", "the path the content read with --stdin is checked as, its .editorconfig properties and excludes apply")
+	flag.BoolVar(&cmdlineConfig.Progress, "progress", false, "show the number of checked files while checking, if stderr is a terminal")
+	flag.BoolVar(&cmdlineConfig.Unordered, "unordered", false, "print the errors of every file as soon as it is checked instead of in the order of the files")
+	flag.IntVar(&cmdlineConfig.Jobs, "jobs", 0, "the number of files which are read and checked at once, 0 means the number of CPUs")
+	flag.BoolVar(&cmdlineConfig.Watch, "watch", false, "keep running and check the files again whenever they or an .editorconfig change")
+	flag.BoolVar(&cmdlineConfig.NoCache, "no-cache", false, "validate all files instead of reusing the results of unchanged files from previous runs")
+	flag.StringVar(&cmdlineConfig.CacheDir, "cache-dir", "", "the directory the results are cached in (default \""+cache.DefaultDir+"\")")
+	flag.StringVar(&cmdlineConfig.NewLinesOnly, "new-lines-only", "", "only report errors on lines which were added or modified since the given git ref")
+}
+
+// disableCheck returns the function of the flag disabling the rule with the ID, or the IndentSizeCheck
+func disableCheck(id string) func(string) error {
+	return func(value string) error {
+		disabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		if cmdlineConfig.Disable.Rules == nil {
+			cmdlineConfig.Disable.Rules = make(map[string]bool)
+		}
+		cmdlineConfig.Disable.Rules[id] = disabled
+		return nil
+	}
 }
 
 // parse the arguments from os.Args
@@ -189,6 +251,13 @@ func parseArguments() {
 
 // Main function, dude
 func main() {
+	if len(os.Args) > 1 {
+		if subcommand, ok := subcommands[os.Args[1]]; ok {
+			subcommand(os.Args[2:])
+			return
+		}
+	}
+
 	parseArguments()
 
 	if cpuprofile != "" {
@@ -204,6 +273,54 @@ func main() {
 	}
 
 	config := *currentConfig
+	state := run.New()
+	state.ReloadEditorconfigs(&config)
+	if (config.Staged && config.Ref != "") || (config.Stdin && (config.Staged || config.Ref != "")) {
+		config.Logger.Error("--staged, --ref and --stdin cannot be combined")
+		exitProxy(exitCodeErrorOccurred)
+	}
+	if config.Stdin {
+		if config.StdinFilename == "" {
+			config.Logger.Error("--stdin requires --stdin-filename")
+			exitProxy(exitCodeErrorOccurred)
+		}
+		content, err := io.ReadAll(stdin)
+		if err != nil {
+			config.Logger.Error("Reading stdin: %v", err.Error())
+			exitProxy(exitCodeErrorOccurred)
+		}
+		state.Source = source.NewBuffer(config.StdinFilename, content)
+	}
+	if config.Staged {
+		index, err := source.NewIndex()
+		if err != nil {
+			config.Logger.Error("%v", err.Error())
+			exitProxy(exitCodeErrorOccurred)
+		}
+		defer index.Close()
+		state.Source = index
+	}
+	if config.Ref != "" {
+		tree, err := source.NewTree(config.Ref)
+		if err != nil {
+			config.Logger.Error("%v", err.Error())
+			exitProxy(exitCodeErrorOccurred)
+		}
+		defer tree.Close()
+		state.Source = tree
+		config.EditorconfigConfig = &editorconfig.Config{Parser: source.NewParser(tree)}
+	}
+
+	if config.Jobs < 0 {
+		config.Logger.Error("--jobs must not be negative")
+		exitProxy(exitCodeErrorOccurred)
+	}
+
+	if err := validation.CheckRules(config); err != nil {
+		config.Logger.Error("%v", err.Error())
+		exitProxy(exitCodeErrorOccurred)
+	}
+
 	// force the exclude regexp to be compiled and cached
 	if _, err := config.CachedExcludesAsRegexp(); err != nil {
 		config.Logger.Error("Compiling exclude regexp: %v", err.Error())
@@ -225,32 +342,168 @@ func main() {
 		exitProxy(exitCodeNormal)
 	}
 
+	// the files are discovered, checked and printed at the same time, unless all errors are needed at once
+	// or the total number of files is needed for the progress
+	if eccerror.IsStreamable(config.Format) && config.Baseline == "" && config.BaselineWrite == "" && !showProgress(config) && !config.DryRun && !config.Watch {
+		state.Cache = loadCache(config)
+		streamErrors(newLinesFilter(config), config, state)
+	}
+
+	// watching discovers the files itself
+	if config.Watch && !config.DryRun {
+		state.Cache = loadCache(config)
+		watchFiles(config, state)
+	}
+
 	// contains all files which should be checked
-	filePaths, err := files.GetFiles(config)
+	discovered, err := files.DiscoverAll(context.Background(), config, state)
 	if err != nil {
 		config.Logger.Error("%v", err.Error())
 		exitProxy(exitCodeErrorOccurred)
 	}
 
 	if config.DryRun {
-		for _, file := range filePaths {
-			config.Logger.Output("%s", file)
+		for _, file := range discovered {
+			config.Logger.Output("%s", file.Path)
 		}
 
 		exitProxy(exitCodeNormal)
 	}
 
-	errors := validation.ProcessValidation(filePaths, config)
+	state.Cache = loadCache(config)
+
+	filter := newLinesFilter(config)
+
+	var progressLine *progress
+	if showProgress(config) {
+		progressLine = &progress{writer: os.Stderr, total: len(discovered)}
+		state.Events = progressLine
+	}
+
+	errors, _, _ := validation.ProcessDiscoveredValidation(context.Background(), discovered, config, state)
+
+	if progressLine != nil {
+		progressLine.done()
+	}
+
+	saveCache(config, state)
+
+	if filter != nil {
+		errors = filter.Apply(errors, config)
+	}
+
+	if config.BaselineWrite != "" {
+		newBaseline := baseline.New(errors)
+		if err := newBaseline.Save(config.BaselineWrite); err != nil {
+			config.Logger.Error("Writing baseline %s: %v", config.BaselineWrite, err.Error())
+			exitProxy(exitCodeErrorOccurred)
+		}
+		config.Logger.Output("%d errors recorded in %s", eccerror.GetErrorCount(errors), config.BaselineWrite)
+		exitProxy(exitCodeNormal)
+	}
+
+	if config.Baseline != "" {
+		knownErrors, err := baseline.Load(config.Baseline)
+		if err != nil {
+			config.Logger.Error("Loading baseline: %v", err.Error())
+			exitProxy(exitCodeErrorOccurred)
+		}
+
+		var staleEntries []baseline.Entry
+		errors, staleEntries = knownErrors.Filter(errors)
+		baseline.PrintStaleEntries(staleEntries, config)
+	}
 
 	eccerror.PrintErrors(errors, config)
 
-	config.Logger.Verbose("%d files checked", len(filePaths))
+	finish(len(discovered), eccerror.GetFailingErrorCount(errors), config)
+}
+
+// streamErrors discovers and checks the files and prints the errors of every file as soon as it is checked, then exits
+// The errors of the files checked before a file cannot be listed are printed before that error.
+func streamErrors(filter *newlines.Filter, config config.Config, state *run.State) {
+	found := make(chan files.File)
+	discoverErr := make(chan error, 1)
+	go func() {
+		discoverErr <- files.Discover(context.Background(), config, state, found)
+	}()
+
+	// count the files while passing them on, the skipped ones are passed too, so the printer knows all indexes
+	fileCount := 0
+	counted := make(chan files.File)
+	go func() {
+		defer close(counted)
+		for file := range found {
+			if !file.Skipped {
+				fileCount++
+			}
+			counted <- file
+		}
+	}()
+
+	printer := eccerror.NewStreamPrinter(config, !config.Unordered)
+	// the context is never cancelled, so there is no error
+	_ = validation.StreamDiscoveredValidation(context.Background(), counted, config, state, func(index int, fileErrors eccerror.ValidationErrors) {
+		if filter != nil {
+			fileErrors = filter.Apply([]eccerror.ValidationErrors{fileErrors}, config)[0]
+		}
+		printer.Print(index, fileErrors)
+	})
+	printer.Close()
+
+	if err := <-discoverErr; err != nil {
+		config.Logger.Error("%v", err.Error())
+		exitProxy(exitCodeErrorOccurred)
+	}
+
+	saveCache(config, state)
+
+	finish(fileCount, printer.FailingErrorCount(), config)
+}
+
+// loadCache returns the results of the previous runs, or nil if the cache is not used
+func loadCache(config config.Config) *cache.Cache {
+	if config.NoCache {
+		return nil
+	}
+	cacheDir := config.CacheDir
+	if cacheDir == "" {
+		cacheDir = defaultCacheDir
+	}
+	return cache.Load(cacheDir, version+config.ValidationHash())
+}
+
+// newLinesFilter returns the filter of the errors on lines which did not change, or nil if all errors are reported
+func newLinesFilter(config config.Config) *newlines.Filter {
+	if config.NewLinesOnly == "" {
+		return nil
+	}
+	filter, err := newlines.New(config.NewLinesOnly)
+	if err != nil {
+		config.Logger.Error("%v", err.Error())
+		exitProxy(exitCodeErrorOccurred)
+	}
+	return &filter
+}
+
+// saveCache saves the results of the run for the next one, if the cache is used
+func saveCache(config config.Config, state *run.State) {
+	if state.Cache != nil {
+		if err := state.Cache.Save(); err != nil {
+			config.Logger.Warning("Could not save the cache: %v", err.Error())
+		}
+	}
+}
+
+// finish stops the CPU profile and exits with an error if any of the errors fail the run
+func finish(fileCount int, failingErrorCount int, config config.Config) {
+	config.Logger.Verbose("%d files checked", fileCount)
 
 	if cpuprofile != "" {
 		pprof.StopCPUProfile()
 	}
 
-	if eccerror.GetErrorCount(errors) != 0 {
+	if failingErrorCount != 0 {
 		exitProxy(exitCodeErrorOccurred)
 	}
 
@@ -265,6 +518,8 @@ func ReturnableFlags(config config.Config) bool {
 	case config.Help:
 		config.Logger.Output("USAGE:")
 		config.Logger.Output("  editorconfig-checker [OPTIONS] [FILE...]")
+		config.Logger.Output("  editorconfig-checker infer-editorconfig [OPTIONS] [FILE...]")
+		config.Logger.Output("  editorconfig-checker fmt-editorconfig [OPTIONS] [FILE...]")
 		config.Logger.Output("")
 		config.Logger.Output("With no FILE arguments, all files tracked by git are checked. When one or")
 		config.Logger.Output("more FILE arguments are given, only those files are checked (the configured")
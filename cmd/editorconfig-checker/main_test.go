@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
@@ -10,6 +11,7 @@ import (
 	"github.com/gkampitakis/go-snaps/snaps"
 
 	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/events"
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/outputformat"
 	// x-release-please-end
 )
@@ -126,6 +128,269 @@ func TestMainDryRun(t *testing.T) {
 	}
 }
 
+func TestMainInferEditorconfig(t *testing.T) {
+	cdRelativeToRepo(t, "")
+	output, lastSeenCode := runWithArguments(t, "infer-editorconfig", "cmd")
+	if lastSeenCode != exitCodeNormal {
+		t.Errorf("main exited with return code %d, but we expected %d", lastSeenCode, exitCodeNormal)
+		t.Logf("Output:\n%s", output)
+	}
+	if !strings.Contains(output, "[*.go]\nindent_style = tab\n") {
+		t.Errorf("main did not propose tab indentation for go files\nOutput:\n%s", output)
+	}
+}
+
+func TestMainInferEditorconfigInvalidPercentile(t *testing.T) {
+	output, lastSeenCode := runWithArguments(t, "infer-editorconfig", "--max-line-length-percentile", "0")
+	if lastSeenCode != exitCodeErrorOccurred {
+		t.Errorf("main exited with return code %d, but we expected %d", lastSeenCode, exitCodeErrorOccurred)
+		t.Logf("Output:\n%s", output)
+	}
+}
+
+func TestMainFmtEditorconfig(t *testing.T) {
+	editorconfigPath := filepath.Join(t.TempDir(), ".editorconfig")
+	if err := os.WriteFile(editorconfigPath, []byte("[*]\nIndent_Style=Tab\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// the mode is set explicitly, so it does not depend on the umask
+	if err := os.Chmod(editorconfigPath, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	output, lastSeenCode := runWithArguments(t, "fmt-editorconfig", "--check", editorconfigPath)
+	if lastSeenCode != exitCodeErrorOccurred {
+		t.Errorf("main exited with return code %d, but we expected %d", lastSeenCode, exitCodeErrorOccurred)
+		t.Logf("Output:\n%s", output)
+	}
+
+	output, lastSeenCode = runWithArguments(t, "fmt-editorconfig", editorconfigPath)
+	if lastSeenCode != exitCodeNormal {
+		t.Errorf("main exited with return code %d, but we expected %d", lastSeenCode, exitCodeNormal)
+		t.Logf("Output:\n%s", output)
+	}
+
+	content, err := os.ReadFile(editorconfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "[*]\nindent_style = tab\n" {
+		t.Errorf("fmt-editorconfig did not rewrite the file, got:\n%s", content)
+	}
+	fileInfo, err := os.Stat(editorconfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileInfo.Mode().Perm() != 0o600 {
+		t.Errorf("fmt-editorconfig did not keep the mode of the file, got %v", fileInfo.Mode().Perm())
+	}
+
+	output, lastSeenCode = runWithArguments(t, "fmt-editorconfig", "--check", editorconfigPath)
+	if lastSeenCode != exitCodeNormal {
+		t.Errorf("main exited with return code %d, but we expected %d", lastSeenCode, exitCodeNormal)
+		t.Logf("Output:\n%s", output)
+	}
+}
+
+func TestMainBaseline(t *testing.T) {
+	baselinePath := filepath.Join(t.TempDir(), "baseline.json")
+	args := []string{`--exclude=""`, "--ignore-defaults", "testdata/trailing-whitespace.txt"}
+
+	output, lastSeenCode := runWithArguments(t, append([]string{"--baseline-write", baselinePath}, args...)...)
+	if lastSeenCode != exitCodeNormal {
+		t.Errorf("main exited with return code %d, but we expected %d", lastSeenCode, exitCodeNormal)
+		t.Logf("Output:\n%s", output)
+	}
+
+	output, lastSeenCode = runWithArguments(t, append([]string{"--baseline", baselinePath}, args...)...)
+	if lastSeenCode != exitCodeNormal {
+		t.Errorf("main exited with return code %d, but we expected %d", lastSeenCode, exitCodeNormal)
+		t.Logf("Output:\n%s", output)
+	}
+
+	output, lastSeenCode = runWithArguments(t, append([]string{"--baseline", filepath.Join(t.TempDir(), "nonexistent.json")}, args...)...)
+	if lastSeenCode != exitCodeErrorOccurred {
+		t.Errorf("main exited with return code %d, but we expected %d", lastSeenCode, exitCodeErrorOccurred)
+		t.Logf("Output:\n%s", output)
+	}
+}
+
+func TestMainStdin(t *testing.T) {
+	cdRelativeToRepo(t, "")
+	t.Cleanup(func() {
+		stdin = os.Stdin
+	})
+
+	// spaces are wrong for go files, but right for the others
+	stdin = strings.NewReader("func main() {\n    return\n}\n")
+	output, lastSeenCode := runWithArguments(t, "--stdin", "--stdin-filename", "cmd/unsaved.go", "--format", "gcc")
+	if lastSeenCode != exitCodeErrorOccurred {
+		t.Errorf("main exited with return code %d, but we expected %d", lastSeenCode, exitCodeErrorOccurred)
+	}
+	if !strings.Contains(output, "cmd/unsaved.go:2:") {
+		t.Errorf("expected an error on line 2 of the stdin filename, got:\n%s", output)
+	}
+
+	stdin = strings.NewReader("func main() {\n    return\n}\n")
+	output, lastSeenCode = runWithArguments(t, "--stdin", "--stdin-filename", "unsaved.txt")
+	if lastSeenCode != exitCodeNormal {
+		t.Errorf("main exited with return code %d, but we expected %d", lastSeenCode, exitCodeNormal)
+		t.Logf("Output:\n%s", output)
+	}
+
+	output, lastSeenCode = runWithArguments(t, "--stdin")
+	if lastSeenCode != exitCodeErrorOccurred {
+		t.Errorf("main exited with return code %d, but we expected %d", lastSeenCode, exitCodeErrorOccurred)
+		t.Logf("Output:\n%s", output)
+	}
+}
+
+func TestMainCache(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFile := func(name string, content string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(".editorconfig", "root = true\n\n[*]\ntrim_trailing_whitespace = true\n")
+	writeFile("file.txt", "trailing \n")
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cachedMessage := "Using the cached result of " + filepath.Join(cwd, "file.txt")
+
+	output, lastSeenCode := runWithArguments(t, "--cache-dir", "cache", "--verbose")
+	if lastSeenCode != exitCodeErrorOccurred {
+		t.Errorf("main exited with return code %d, but we expected %d", lastSeenCode, exitCodeErrorOccurred)
+		t.Logf("Output:\n%s", output)
+	}
+	if _, err := os.Stat(filepath.Join("cache", "results.json")); err != nil {
+		t.Errorf("expected the results to be cached: %v", err)
+	}
+
+	output, lastSeenCode = runWithArguments(t, "--cache-dir", "cache", "--verbose")
+	if lastSeenCode != exitCodeErrorOccurred || !strings.Contains(output, cachedMessage) {
+		t.Errorf("expected the cached error to be reported again, got %d:\n%s", lastSeenCode, output)
+	}
+
+	// the limits decide whether a file is truncated, which changes its errors
+	for _, args := range [][]string{
+		{"--max-file-size", "5", "--large-file-lines", "1"},
+		{"--max-file-size", "5", "--large-file-lines", "2"},
+		{"--max-file-size", "50", "--large-file-lines", "2"},
+	} {
+		output, lastSeenCode = runWithArguments(t, append([]string{"--cache-dir", "cache", "--verbose"}, args...)...)
+		if strings.Contains(output, cachedMessage) {
+			t.Errorf("%v: expected file.txt to be validated again, got %d:\n%s", args, lastSeenCode, output)
+		}
+	}
+
+	// a changed .editorconfig invalidates the cached results of the files it applies to
+	writeFile(".editorconfig", "root = true\n\n[*]\ntrim_trailing_whitespace = false\n")
+	output, lastSeenCode = runWithArguments(t, "--cache-dir", "cache", "--verbose")
+	if lastSeenCode != exitCodeNormal || strings.Contains(output, cachedMessage) {
+		t.Errorf("expected file.txt to be validated again, got %d:\n%s", lastSeenCode, output)
+	}
+}
+
+func TestMainRules(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(".editorconfig", []byte("root = true\n\n[*]\ntrim_trailing_whitespace = true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("file.txt", []byte("trailing \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// warnings are reported without failing the run
+	output, lastSeenCode := runWithArguments(t, "--no-cache", "--format", "gcc", "--rule", "trim-trailing-whitespace=warning")
+	if lastSeenCode != exitCodeNormal || !strings.Contains(output, "warning: Trailing whitespace") ||
+		!strings.Contains(output, "1 warnings found") || strings.Contains(output, "errors found") {
+		t.Errorf("expected the trailing whitespace to be a warning, got %d:\n%s", lastSeenCode, output)
+	}
+
+	output, lastSeenCode = runWithArguments(t, "--no-cache", "--rule", "trim-trailing-whitespace=off")
+	if lastSeenCode != exitCodeNormal || strings.Contains(output, "Trailing whitespace") {
+		t.Errorf("expected the trailing whitespace not to be checked, got %d:\n%s", lastSeenCode, output)
+	}
+
+	// every rule has a flag disabling it
+	output, lastSeenCode = runWithArguments(t, "--no-cache", "--disable-trim-trailing-whitespace")
+	if lastSeenCode != exitCodeNormal || strings.Contains(output, "Trailing whitespace") {
+		t.Errorf("expected the trailing whitespace not to be checked when disabled, got %d:\n%s", lastSeenCode, output)
+	}
+
+	output, lastSeenCode = runWithArguments(t, "--no-cache", "--rule", "unknown-rule=off")
+	if lastSeenCode != exitCodeErrorOccurred || !strings.Contains(output, `unknown rule "unknown-rule"`) {
+		t.Errorf("expected the unknown rule to be rejected, got %d:\n%s", lastSeenCode, output)
+	}
+}
+
+func TestMainUnordered(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(".editorconfig", []byte("root = true\n\n[*]\ntrim_trailing_whitespace = true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := os.WriteFile(name, []byte("trailing \n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, args := range [][]string{{"--no-cache"}, {"--no-cache", "--unordered"}, {"--no-cache", "--jobs", "1"}} {
+		output, lastSeenCode := runWithArguments(t, args...)
+		if lastSeenCode != exitCodeErrorOccurred || strings.Count(output, "Trailing whitespace") != 3 || !strings.Contains(output, "3 errors found") {
+			t.Errorf("%v: expected the errors of all files, got %d:\n%s", args, lastSeenCode, output)
+		}
+	}
+}
+
+func TestMainLargeFileLines(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(".editorconfig", []byte("root = true\n\n[*]\ninsert_final_newline = false\nend_of_line = crlf\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// the first lines end like they should, the end of the file is not checked
+	content := strings.Repeat("line\r\n", 200) + "last line\n" + "no final newline"
+	if err := os.WriteFile("large.txt", []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{{"--no-cache"}, {}} {
+		args = append(args, "--max-file-size", "100", "--large-file-lines", "5", "large.txt")
+		output, lastSeenCode := runWithArguments(t, args...)
+		if lastSeenCode != exitCodeNormal {
+			t.Errorf("%v: expected the end of the file not to be checked, got %d:\n%s", args, lastSeenCode, output)
+		}
+	}
+}
+
+func TestMainNegativeJobs(t *testing.T) {
+	output, lastSeenCode := runWithArguments(t, "--jobs", "-1")
+	if lastSeenCode != exitCodeErrorOccurred || !strings.Contains(output, "--jobs must not be negative") {
+		t.Errorf("expected the negative jobs to be rejected, got %d:\n%s", lastSeenCode, output)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	var buffer bytes.Buffer
+	progressLine := &progress{writer: &buffer, total: 3}
+	progressLine.Notify(events.Event{Kind: events.FileSkipped, FilePath: "excluded.txt"})
+	progressLine.Notify(events.Event{Kind: events.FileStarted, FilePath: "a.txt"})
+	progressLine.Notify(events.Event{Kind: events.FileFinished, FilePath: "a.txt", ErrorCount: 2})
+	progressLine.Notify(events.Event{Kind: events.FileFinished, FilePath: "b.txt"})
+	progressLine.Notify(events.Event{Kind: events.FileStarted, FilePath: "unreadable.txt"})
+	progressLine.Notify(events.Event{Kind: events.FileSkipped, FilePath: "unreadable.txt"})
+	progressLine.done()
+
+	expected := clearLine + "1/3 files checked, 2 errors" + clearLine + "2/3 files checked, 2 errors" + clearLine + "3/3 files checked, 2 errors" + clearLine
+	if buffer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buffer.String())
+	}
+}
+
 func TestMainColorSupport(t *testing.T) {
 	type env map[string]string
 	type args []string
@@ -298,5 +563,14 @@ func TestMain(m *testing.M) {
 	exitProxy = captureReturnCode
 	mainHasRun = make(chan int)
 
-	os.Exit(m.Run())
+	// the tests must not leave a cache in the repository
+	cacheDir, err := os.MkdirTemp("", "editorconfig-checker-cache")
+	if err != nil {
+		panic(err)
+	}
+	defaultCacheDir = cacheDir
+
+	code := m.Run()
+	os.RemoveAll(cacheDir)
+	os.Exit(code)
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/events"
+	// x-release-please-end
+)
+
+// clearLine moves the cursor to the start of the line and erases it
+const clearLine = "\r\x1b[K"
+
+// progress prints the number of checked files on a single line of a terminal while the files are validated
+type progress struct {
+	writer     io.Writer
+	lock       sync.Mutex
+	total      int
+	finished   int
+	errorCount int
+	// started holds the files whose validation started, which are counted as well when they are skipped afterwards
+	started map[string]bool
+}
+
+// Notify updates the line when a file is finished or skipped after its validation started
+func (p *progress) Notify(event events.Event) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	switch event.Kind {
+	case events.FileStarted:
+		if p.started == nil {
+			p.started = map[string]bool{}
+		}
+		p.started[event.FilePath] = true
+		return
+	case events.FileSkipped:
+		if !p.started[event.FilePath] {
+			return
+		}
+	case events.FileFinished:
+	default:
+		return
+	}
+
+	delete(p.started, event.FilePath)
+	p.finished++
+	p.errorCount += event.ErrorCount
+	fmt.Fprintf(p.writer, "%s%d/%d files checked, %d errors", clearLine, p.finished, p.total, p.errorCount)
+}
+
+// done erases the line, so the errors are printed in its place
+func (p *progress) done() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	fmt.Fprint(p.writer, clearLine)
+}
+
+// showProgress returns whether the progress is shown, which is only the case where it can be updated in place
+func showProgress(config config.Config) bool {
+	return config.Progress && isTerminal(os.Stderr)
+}
+
+// isTerminal returns whether the file is an interactive terminal rather than a pipe or a regular file
+func isTerminal(file *os.File) bool {
+	fileInfo, err := file.Stat()
+	return err == nil && fileInfo.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/editorconfigfmt"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/files"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/infer"
+	// x-release-please-end
+)
+
+// subcommands maps the name of a subcommand to the function running it with the remaining arguments
+var subcommands = map[string]func(args []string){
+	"infer-editorconfig": inferEditorconfig,
+	"fmt-editorconfig":   fmtEditorconfig,
+}
+
+// parseSubcommandArguments parses the arguments of a subcommand and loads the config file,
+// the same way parseArguments does it for the checker itself
+func parseSubcommandArguments(flags *flag.FlagSet, args []string) {
+	var subcommandConfigFilePath, subcommandExclude string
+	flags.StringVar(&subcommandConfigFilePath, "config", "", "config")
+	flags.StringVar(&subcommandExclude, "exclude", "", "a regex which files should be excluded - needs to be a valid regular expression. Combine patterns with | (pipe): -exclude \"vendor|testdata\"")
+
+	currentConfig = config.NewConfig(defaultConfigFileNames)
+	loggerInjectionHook()
+	flags.SetOutput(currentConfig.Logger.GetWriter())
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			exitProxy(exitCodeNormal)
+		}
+		exitProxy(exitCodeErrorOccurred)
+	}
+
+	if subcommandConfigFilePath != "" {
+		currentConfig.Path = subcommandConfigFilePath
+	}
+
+	err := currentConfig.Parse()
+	if err != nil && !(subcommandConfigFilePath == "" && errors.Is(err, fs.ErrNotExist)) {
+		currentConfig.Logger.Error("%v", err.Error())
+		exitProxy(exitCodeConfigFileNotFound)
+	}
+
+	subcommandConfig := config.Config{}
+	if subcommandExclude != "" {
+		subcommandConfig.Exclude = append(subcommandConfig.Exclude, subcommandExclude)
+	}
+	for _, arg := range flags.Args() {
+		if arg != "" {
+			subcommandConfig.PassedFiles = append(subcommandConfig.PassedFiles, arg)
+		}
+	}
+	currentConfig.Merge(subcommandConfig)
+
+	if _, err := currentConfig.CachedExcludesAsRegexp(); err != nil {
+		currentConfig.Logger.Error("Compiling exclude regexp: %v", err.Error())
+		exitProxy(exitCodeErrorOccurred)
+	}
+}
+
+// inferEditorconfig prints an .editorconfig proposal derived from the files which would be checked
+func inferEditorconfig(args []string) {
+	flags := flag.NewFlagSet("infer-editorconfig", flag.ContinueOnError)
+	percentile := flags.Float64("max-line-length-percentile", infer.DefaultMaxLineLengthPercentile, "the percentile of the per file longest lines that is proposed as max_line_length")
+	flags.Usage = func() {
+		currentConfig.Logger.Output("USAGE:")
+		currentConfig.Logger.Output("  editorconfig-checker infer-editorconfig [OPTIONS] [FILE...]")
+		currentConfig.Logger.Output("")
+		currentConfig.Logger.Output("Prints an .editorconfig with one section per file extension, inferred from the")
+		currentConfig.Logger.Output("files which would be checked.")
+		currentConfig.Logger.Output("")
+		currentConfig.Logger.Output("OPTIONS:")
+		flags.PrintDefaults()
+	}
+	parseSubcommandArguments(flags, args)
+
+	if *percentile <= 0 || *percentile > 100 {
+		currentConfig.Logger.Error("The max line length percentile must be within (0, 100], got %v", *percentile)
+		exitProxy(exitCodeErrorOccurred)
+	}
+
+	config := *currentConfig
+
+	filePaths, err := files.GetFiles(config)
+	if err != nil {
+		config.Logger.Error("%v", err.Error())
+		exitProxy(exitCodeErrorOccurred)
+	}
+
+	proposal, err := infer.Infer(filePaths, config, *percentile)
+	if err != nil {
+		config.Logger.Error("%v", err.Error())
+		exitProxy(exitCodeErrorOccurred)
+	}
+
+	config.Logger.Output("%s", strings.TrimSuffix(proposal.String(), "\n"))
+	exitProxy(exitCodeNormal)
+}
+
+// fmtEditorconfig rewrites the .editorconfig files into their canonical form,
+// or only reports the ones which are not in their canonical form with -check
+func fmtEditorconfig(args []string) {
+	flags := flag.NewFlagSet("fmt-editorconfig", flag.ContinueOnError)
+	check := flags.Bool("check", false, "do not rewrite the files, but exit with an error if any of them is not formatted")
+	flags.Usage = func() {
+		currentConfig.Logger.Output("USAGE:")
+		currentConfig.Logger.Output("  editorconfig-checker fmt-editorconfig [OPTIONS] [FILE...]")
+		currentConfig.Logger.Output("")
+		currentConfig.Logger.Output("Rewrites .editorconfig files into their canonical form. With no FILE arguments,")
+		currentConfig.Logger.Output("all .editorconfig files which would be checked are formatted.")
+		currentConfig.Logger.Output("")
+		currentConfig.Logger.Output("OPTIONS:")
+		flags.PrintDefaults()
+	}
+	parseSubcommandArguments(flags, args)
+
+	config := *currentConfig
+
+	filePaths, err := files.GetFiles(config)
+	if err != nil {
+		config.Logger.Error("%v", err.Error())
+		exitProxy(exitCodeErrorOccurred)
+	}
+
+	unformattedCount := 0
+	for _, filePath := range filePaths {
+		// explicitly passed files are formatted regardless of their name
+		if filepath.Base(filePath) != ".editorconfig" && !slices.Contains(config.PassedFiles, filePath) {
+			continue
+		}
+
+		// the file is written back with its original mode
+		fileInfo, err := os.Stat(filePath)
+		if err != nil {
+			config.Logger.Error("%v", err.Error())
+			exitProxy(exitCodeErrorOccurred)
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			config.Logger.Error("%v", err.Error())
+			exitProxy(exitCodeErrorOccurred)
+		}
+
+		formatted, err := editorconfigfmt.Format(content)
+		if err != nil {
+			config.Logger.Error("Formatting %s: %v", filePath, err.Error())
+			exitProxy(exitCodeErrorOccurred)
+		}
+
+		if bytes.Equal(content, formatted) {
+			config.Logger.Verbose("%s is formatted", filePath)
+			continue
+		}
+
+		unformattedCount++
+		if *check {
+			config.Logger.Error("%s is not formatted", filePath)
+			continue
+		}
+
+		if err := os.WriteFile(filePath, formatted, fileInfo.Mode().Perm()); err != nil {
+			config.Logger.Error("%v", err.Error())
+			exitProxy(exitCodeErrorOccurred)
+		}
+		config.Logger.Output("%s", filePath)
+	}
+
+	if *check && unformattedCount != 0 {
+		exitProxy(exitCodeErrorOccurred)
+	}
+	exitProxy(exitCodeNormal)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/signal"
+	"time"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/baseline"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	eccerror "github.com/editorconfig-checker/editorconfig-checker/v3/pkg/error"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/newlines"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/run"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/validation"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/watch"
+	// x-release-please-end
+)
+
+// clearScreen moves the cursor to the top left corner and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// watchFiles checks the files whenever they change and prints the errors of all files each time, until interrupted
+func watchFiles(config config.Config, state *run.State) {
+	if config.Staged || config.Ref != "" || config.Stdin || config.BaselineWrite != "" {
+		config.Logger.Error("--watch cannot be combined with --staged, --ref, --stdin or --baseline-write")
+		exitProxy(exitCodeErrorOccurred)
+	}
+
+	var knownErrors baseline.Baseline
+	if config.Baseline != "" {
+		var err error
+		knownErrors, err = baseline.Load(config.Baseline)
+		if err != nil {
+			config.Logger.Error("Loading baseline: %v", err.Error())
+			exitProxy(exitCodeErrorOccurred)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := watch.Run(ctx, config, state, watch.DefaultInterval, configReloader(&config, state), func(errors []eccerror.ValidationErrors) {
+		if config.NewLinesOnly != "" {
+			// the added lines change with the files
+			filter, err := newlines.New(config.NewLinesOnly)
+			if err != nil {
+				config.Logger.Error("%v", err.Error())
+				return
+			}
+			errors = filter.Apply(errors, config)
+		}
+		if config.Baseline != "" {
+			errors, _ = knownErrors.Filter(errors)
+		}
+
+		if !config.NoColor {
+			config.Logger.Output("%s", clearScreen)
+		}
+		eccerror.PrintErrors(errors, config)
+		config.Logger.Output("%s: %d errors and %d warnings in %d files, watching for changes (press Ctrl+C to stop)",
+			time.Now().Format(time.TimeOnly), eccerror.GetFailingErrorCount(errors), eccerror.GetWarningCount(errors), len(errors))
+	})
+	if err != nil {
+		config.Logger.Error("%v", err.Error())
+		exitProxy(exitCodeErrorOccurred)
+	}
+
+	exitProxy(exitCodeNormal)
+}
+
+// configReloader returns the function which reads the config file again when it changes while watching.
+// The reloaded config replaces the current one, and the cache is loaded again for it
+func configReloader(current *config.Config, state *run.State) func() (config.Config, error) {
+	return func() (config.Config, error) {
+		reloaded, err := reloadConfig(*current)
+		if err != nil {
+			return *current, err
+		}
+		saveCache(*current, state)
+		state.Cache = loadCache(reloaded)
+		*current = reloaded
+		return reloaded, nil
+	}
+}
+
+// reloadConfig reads the config file of the current config again and applies the arguments to it like parseArguments
+func reloadConfig(current config.Config) (config.Config, error) {
+	reloaded := config.NewConfig([]string{current.Path})
+	reloaded.Logger = current.Logger
+	// a removed config file means the defaults are used
+	if err := reloaded.Parse(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return current, err
+	}
+	reloaded.Merge(cmdlineConfig)
+
+	if err := validation.CheckRules(*reloaded); err != nil {
+		return current, err
+	}
+	if _, err := reloaded.CachedExcludesAsRegexp(); err != nil {
+		return current, err
+	}
+	return *reloaded, nil
+}
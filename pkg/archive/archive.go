@@ -0,0 +1,337 @@
+// Package archive reads the files inside of tar and zip archives, so they can be checked without extracting them
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/resolver"
+	// x-release-please-end
+)
+
+// Separator separates the path of an archive from the path of a file inside of it,
+// like in archive.tar.gz!/path/in/archive
+const Separator = "!/"
+
+// extensions are the file extensions of the supported archives
+var extensions = []string{".tar", ".tar.gz", ".tgz", ".zip"}
+
+// IsArchive returns whether the file is a supported archive, judged by its extension
+func IsArchive(filePath string) bool {
+	lowerFilePath := strings.ToLower(filePath)
+	for _, extension := range extensions {
+		if strings.HasSuffix(lowerFilePath, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// Split splits the path of a file inside of an archive into the path of the archive and the path inside of it.
+// It returns false if the path is not inside of an archive.
+func Split(filePath string) (string, string, bool) {
+	filePath = filepath.ToSlash(filePath)
+	for offset := 0; ; {
+		index := strings.Index(filePath[offset:], Separator)
+		if index == -1 {
+			return "", "", false
+		}
+		index += offset
+		if IsArchive(filePath[:index]) {
+			return filepath.FromSlash(filePath[:index]), filePath[index+len(Separator):], true
+		}
+		offset = index + len(Separator)
+	}
+}
+
+// IsEntry returns whether the path is a file inside of an archive
+func IsEntry(filePath string) bool {
+	_, _, ok := Split(filePath)
+	return ok
+}
+
+// Archive holds the regular files of an archive in memory
+type Archive struct {
+	files map[string]entry
+}
+
+// entry is a regular file of an archive, of which only the first bytes may be held in memory
+type entry struct {
+	content []byte
+	// size is the size of the whole file
+	size int64
+}
+
+// Read reads the regular files of a tar, gzip compressed tar or zip archive.
+// Directories and links are skipped.
+func Read(filePath string) (*Archive, error) {
+	return ReadLimited(filePath, 0)
+}
+
+// ReadLimited reads the regular files of an archive like Read, but holds only the first maxEntrySize bytes
+// of every file in memory, unless maxEntrySize is 0. The archive itself is streamed from its file.
+func ReadLimited(filePath string, maxEntrySize int64) (*Archive, error) {
+	archive := &Archive{files: make(map[string]entry)}
+	err := walk(filePath, func(name string, content io.Reader, size int64) error {
+		if maxEntrySize > 0 {
+			content = io.LimitReader(content, maxEntrySize)
+		}
+		read, err := io.ReadAll(content)
+		if err != nil {
+			return err
+		}
+		archive.files[name] = entry{content: read, size: max(size, int64(len(read)))}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading archive %s: %w", filePath, err)
+	}
+
+	return archive, nil
+}
+
+// errFound stops walking an archive once the file looked for is read
+var errFound = errors.New("found")
+
+// readWhole reads the whole content of a single file inside of an archive, streaming the archive up to it
+func readWhole(filePath string, name string) ([]byte, error) {
+	var content []byte
+	err := walk(filePath, func(entryName string, reader io.Reader, _ int64) error {
+		if entryName != name {
+			return nil
+		}
+		var err error
+		if content, err = io.ReadAll(reader); err != nil {
+			return err
+		}
+		return errFound
+	})
+	if errors.Is(err, errFound) {
+		return content, nil
+	}
+	if err == nil {
+		err = fs.ErrNotExist
+	}
+	return nil, fmt.Errorf("reading %s in archive %s: %w", name, filePath, err)
+}
+
+// walk calls add with the name, the content and the size of every regular file of a tar, gzip compressed tar or zip archive,
+// a name pointing outside of the archive, like ../file, is kept inside.
+// Directories and links are skipped, and the content which is not read by add is skipped as well.
+func walk(filePath string, add func(name string, content io.Reader, size int64) error) error {
+	addFile := func(name string, content io.Reader, size int64) error {
+		name = strings.TrimPrefix(path.Clean("/"+name), "/")
+		if name == "" {
+			return nil
+		}
+		return add(name, content, size)
+	}
+	if strings.HasSuffix(strings.ToLower(filePath), ".zip") {
+		return walkZip(filePath, addFile)
+	}
+	return walkTar(filePath, addFile)
+}
+
+func walkTar(filePath string, add func(name string, content io.Reader, size int64) error) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if !strings.HasSuffix(strings.ToLower(filePath), ".tar") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := add(header.Name, tarReader, header.Size); err != nil {
+			return err
+		}
+	}
+}
+
+func walkZip(filePath string, add func(name string, content io.Reader, size int64) error) error {
+	zipReader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return err
+	}
+	defer zipReader.Close()
+
+	for _, file := range zipReader.File {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = add(file.Name, reader, int64(file.UncompressedSize64))
+		reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Files returns the paths of the files inside of the archive
+func (a *Archive) Files() []string {
+	names := make([]string, 0, len(a.files))
+	for name := range a.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Cache holds the archives which were read by their absolute path, so an archive is read once
+// rather than for every file inside of it, and again only if it was modified since.
+// It is meant to be used for one run, so the archives are not held in memory any longer.
+// It is safe for concurrent use, a nil Cache reads the archive every time.
+type Cache struct {
+	// MaxEntrySize is the number of bytes of every file inside of an archive which are held in memory,
+	// all of them if it is 0. It is to be set before the first archive is read.
+	MaxEntrySize int64
+
+	lock   sync.Mutex
+	byPath map[string]cachedArchive
+}
+
+// cachedArchive is an archive with the modification time and size of its file when it was read
+type cachedArchive struct {
+	archive *Archive
+	modTime time.Time
+	size    int64
+}
+
+// NewCache creates an empty Cache
+func NewCache() *Cache {
+	return &Cache{byPath: make(map[string]cachedArchive)}
+}
+
+// open returns the archive at the path, which is only read if it is not cached or was modified
+func (c *Cache) open(archivePath string) (*Archive, error) {
+	if c == nil {
+		return Read(archivePath)
+	}
+
+	absolutePath, err := filepath.Abs(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	fileStat, err := os.Stat(absolutePath)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if cached, ok := c.byPath[absolutePath]; ok && cached.modTime.Equal(fileStat.ModTime()) && cached.size == fileStat.Size() {
+		return cached.archive, nil
+	}
+	archive, err := ReadLimited(absolutePath, c.MaxEntrySize)
+	if err != nil {
+		return nil, err
+	}
+	c.byPath[absolutePath] = cachedArchive{archive: archive, modTime: fileStat.ModTime(), size: fileStat.Size()}
+	return archive, nil
+}
+
+// List returns the paths of the files inside of the archive, joined to the path of the archive with the Separator
+func (c *Cache) List(archivePath string) ([]string, error) {
+	archive, err := c.open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	filePaths := archive.Files()
+	for i, name := range filePaths {
+		filePaths[i] = archivePath + Separator + name
+	}
+	return filePaths, nil
+}
+
+// ReadFile returns the content of a file inside of an archive, like archive.tar.gz!/path/in/archive.
+// A file larger than the MaxEntrySize is read from the archive again as a whole.
+// The error wraps fs.ErrNotExist if the archive does not contain the file.
+func (c *Cache) ReadFile(filePath string) ([]byte, error) {
+	content, size, err := c.ReadFileWithSize(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if size > int64(len(content)) {
+		archivePath, name, _ := Split(filePath)
+		return readWhole(archivePath, path.Clean(name))
+	}
+	return content, nil
+}
+
+// ReadFileWithSize returns the content of a file inside of an archive like ReadFile along with the size of the whole file,
+// of which only the first MaxEntrySize bytes are returned
+func (c *Cache) ReadFileWithSize(filePath string) ([]byte, int64, error) {
+	archivePath, name, ok := Split(filePath)
+	if !ok {
+		return nil, 0, fmt.Errorf("%s is not inside of an archive", filePath)
+	}
+
+	archive, err := c.open(archivePath)
+	if err != nil {
+		return nil, 0, err
+	}
+	file, ok := archive.files[path.Clean(name)]
+	if !ok {
+		return nil, 0, fmt.Errorf("%s does not exist in %s: %w", name, archivePath, fs.ErrNotExist)
+	}
+	return file.content, file.size, nil
+}
+
+// NewParser creates a parser which reads the .editorconfig files inside of archives from the archives of the cache,
+// and all others from the file system
+func NewParser(cache *Cache) *resolver.Parser {
+	return resolver.NewParser(fileReader{cache})
+}
+
+// fileReader reads the files inside of archives from the archives of a cache, and all others from the file system
+type fileReader struct {
+	cache *Cache
+}
+
+func (r fileReader) ReadFile(filename string) ([]byte, error) {
+	if IsEntry(filename) {
+		return r.cache.ReadFile(filename)
+	}
+	return os.ReadFile(filename)
+}
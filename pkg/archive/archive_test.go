@@ -0,0 +1,218 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+)
+
+var archiveFiles = map[string]string{
+	".editorconfig":  "root = true\n\n[*.txt]\nindent_style = tab\n",
+	"sub/file.txt":   "content\n",
+	"../outside.txt": "outside\n",
+}
+
+func writeTarGz(t *testing.T, filePath string) {
+	t.Helper()
+	file, err := os.Create(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	if err := tarWriter.WriteHeader(&tar.Header{Name: "sub/", Typeflag: tar.TypeDir, Mode: 0o755}); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range archiveFiles {
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeZip(t *testing.T, filePath string) {
+	t.Helper()
+	file, err := os.Create(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	zipWriter := zip.NewWriter(file)
+	for name, content := range archiveFiles {
+		writer, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		filePath    string
+		archivePath string
+		entry       string
+		ok          bool
+	}{
+		{"archive.tar.gz!/sub/file.txt", "archive.tar.gz", "sub/file.txt", true},
+		{"dir/archive.ZIP!/file.txt", filepath.FromSlash("dir/archive.ZIP"), "file.txt", true},
+		{"wow!/archive.tgz!/file.txt", filepath.FromSlash("wow!/archive.tgz"), "file.txt", true},
+		{"archive.tar.gz", "", "", false},
+		{"file.txt!/other.txt", "", "", false},
+	}
+
+	for _, test := range tests {
+		archivePath, entry, ok := Split(test.filePath)
+		if archivePath != test.archivePath || entry != test.entry || ok != test.ok {
+			t.Errorf("Split(%q): expected %q, %q, %v, got %q, %q, %v",
+				test.filePath, test.archivePath, test.entry, test.ok, archivePath, entry, ok)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	tarGzPath := filepath.Join(dir, "archive.tar.gz")
+	zipPath := filepath.Join(dir, "archive.zip")
+	writeTarGz(t, tarGzPath)
+	writeZip(t, zipPath)
+
+	cache := NewCache()
+	for _, archivePath := range []string{tarGzPath, zipPath} {
+		filePaths, err := cache.List(archivePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// directories are skipped and names pointing outside are kept inside of the archive
+		expected := []string{
+			archivePath + "!/.editorconfig",
+			archivePath + "!/outside.txt",
+			archivePath + "!/sub/file.txt",
+		}
+		if !reflect.DeepEqual(filePaths, expected) {
+			t.Errorf("List(%q): expected %v, got %v", archivePath, expected, filePaths)
+		}
+
+		content, err := cache.ReadFile(archivePath + "!/sub/file.txt")
+		if err != nil || string(content) != "content\n" {
+			t.Errorf("ReadFile(%q): expected %q, got %q, %v", archivePath+"!/sub/file.txt", "content\n", content, err)
+		}
+		if _, err := cache.ReadFile(archivePath + "!/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("ReadFile(%q): expected %v, got %v", archivePath+"!/missing.txt", fs.ErrNotExist, err)
+		}
+	}
+
+	broken := filepath.Join(dir, "broken.zip")
+	if err := os.WriteFile(broken, []byte("not a zip"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.List(broken); err == nil {
+		t.Errorf("List(%q): expected an error, got nil", broken)
+	}
+}
+
+func TestMaxEntrySize(t *testing.T) {
+	dir := t.TempDir()
+	tarGzPath := filepath.Join(dir, "archive.tar.gz")
+	zipPath := filepath.Join(dir, "archive.zip")
+	writeTarGz(t, tarGzPath)
+	writeZip(t, zipPath)
+
+	cache := NewCache()
+	cache.MaxEntrySize = 4
+	for _, archivePath := range []string{tarGzPath, zipPath} {
+		// only the first bytes of a larger file are held in memory, along with the size of the whole file
+		filePath := archivePath + "!/sub/file.txt"
+		content, size, err := cache.ReadFileWithSize(filePath)
+		if err != nil || string(content) != "cont" || size != int64(len("content\n")) {
+			t.Errorf("ReadFileWithSize(%q): expected %q of %d bytes, got %q of %d bytes, %v", filePath, "cont", len("content\n"), content, size, err)
+		}
+		// the whole file is read from the archive again
+		if content, err := cache.ReadFile(filePath); err != nil || string(content) != "content\n" {
+			t.Errorf("ReadFile(%q): expected %q, got %q, %v", filePath, "content\n", content, err)
+		}
+		if _, err := cache.ReadFile(archivePath + "!/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("ReadFile(%q): expected %v, got %v", archivePath+"!/missing.txt", fs.ErrNotExist, err)
+		}
+	}
+}
+
+func TestCacheReadsModifiedArchives(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "archive.zip")
+	writeZip(t, archivePath)
+
+	cache := NewCache()
+	if _, err := cache.ReadFile(archivePath + "!/sub/file.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the archive is read again once it is modified
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zipWriter := zip.NewWriter(file)
+	writer, err := zipWriter.Create("sub/file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.Write([]byte("modified\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(time.Hour)
+	if err := os.Chtimes(archivePath, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cache := range []*Cache{cache, nil} {
+		content, err := cache.ReadFile(archivePath + "!/sub/file.txt")
+		if err != nil || string(content) != "modified\n" {
+			t.Errorf("ReadFile(modified archive): expected %q, got %q, %v", "modified\n", content, err)
+		}
+	}
+}
+
+func TestParser(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "archive.tgz")
+	writeTarGz(t, archivePath)
+
+	config := &editorconfig.Config{Parser: NewParser(NewCache())}
+	definition, warning, err := config.LoadGraceful(archivePath + "!/sub/file.txt")
+	if err != nil || warning != nil {
+		t.Fatal(err, warning)
+	}
+	if definition.IndentStyle != "tab" {
+		t.Errorf("expected the .editorconfig of the archive to apply, got %+v", definition)
+	}
+}
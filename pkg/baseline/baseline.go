@@ -0,0 +1,173 @@
+// Package baseline records known validation errors, so only new ones are reported
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	eccerror "github.com/editorconfig-checker/editorconfig-checker/v3/pkg/error"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/files"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/outputformat"
+	// x-release-please-end
+)
+
+// Version is the version of the baseline file format
+const Version = 1
+
+// Entry represents identical errors of one rule on lines with the same content in a file.
+// Errors are matched by the content of their line instead of the line number,
+// so an entry still matches after lines were added or removed elsewhere in the file.
+type Entry struct {
+	Path     string
+	Rule     string
+	LineHash string `json:",omitempty"`
+	// Message is the message of the first error, to make the file readable, it is not used for matching
+	Message string
+	Count   int
+}
+
+// key is what errors are matched on
+type key struct {
+	path     string
+	rule     string
+	lineHash string
+}
+
+func (e Entry) key() key {
+	return key{path: e.Path, rule: e.Rule, lineHash: e.LineHash}
+}
+
+// Baseline is the content of a baseline file
+type Baseline struct {
+	Version int
+	Entries []Entry
+}
+
+// relativePath returns the path entries are recorded with
+func relativePath(filePath string) string {
+	relativeFilePath, err := files.GetRelativePath(filePath)
+	if err != nil {
+		return filePath
+	}
+	return relativeFilePath
+}
+
+// New creates a baseline from the errors found
+func New(errors []eccerror.ValidationErrors) Baseline {
+	entries := make(map[key]*Entry)
+	for _, fileErrors := range errors {
+		path := relativePath(fileErrors.FilePath)
+		for _, singleError := range fileErrors.Errors {
+			k := key{path: path, rule: singleError.Rule, lineHash: singleError.LineHash}
+			if entry, ok := entries[k]; ok {
+				entry.Count++
+				continue
+			}
+			entries[k] = &Entry{Path: path, Rule: singleError.Rule, LineHash: singleError.LineHash, Message: singleError.Message.Error(), Count: 1}
+		}
+	}
+
+	baseline := Baseline{Version: Version, Entries: make([]Entry, 0, len(entries))}
+	for _, entry := range entries {
+		baseline.Entries = append(baseline.Entries, *entry)
+	}
+	sort.Slice(baseline.Entries, func(i, j int) bool {
+		a, b := baseline.Entries[i], baseline.Entries[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.LineHash < b.LineHash
+	})
+
+	return baseline
+}
+
+// Load reads a baseline file
+func Load(path string) (Baseline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Baseline{}, err
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(content, &baseline); err != nil {
+		return Baseline{}, fmt.Errorf("parsing baseline %s: %w", path, err)
+	}
+	if baseline.Version != Version {
+		return Baseline{}, fmt.Errorf("baseline %s has version %d, but only version %d is supported", path, baseline.Version, Version)
+	}
+
+	return baseline, nil
+}
+
+// Save writes the baseline to a file
+func (b Baseline) Save(path string) error {
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
+
+// Filter removes the errors recorded in the baseline and returns the remaining, new errors
+// together with the entries of which fewer errors occur than were recorded.
+// Entries of files which were not checked are not considered to be stale.
+func (b Baseline) Filter(errors []eccerror.ValidationErrors) ([]eccerror.ValidationErrors, []Entry) {
+	remaining := make(map[key]int, len(b.Entries))
+	for _, entry := range b.Entries {
+		remaining[entry.key()] += entry.Count
+	}
+
+	checkedPaths := make(map[string]bool, len(errors))
+	newErrors := make([]eccerror.ValidationErrors, 0, len(errors))
+	for _, fileErrors := range errors {
+		path := relativePath(fileErrors.FilePath)
+		checkedPaths[path] = true
+		var unknownErrors []eccerror.ValidationError
+		for _, singleError := range fileErrors.Errors {
+			k := key{path: path, rule: singleError.Rule, lineHash: singleError.LineHash}
+			if remaining[k] > 0 {
+				remaining[k]--
+				continue
+			}
+			unknownErrors = append(unknownErrors, singleError)
+		}
+		newErrors = append(newErrors, eccerror.ValidationErrors{FilePath: fileErrors.FilePath, Errors: unknownErrors})
+	}
+
+	var staleEntries []Entry
+	for _, entry := range b.Entries {
+		if count := remaining[entry.key()]; count > 0 && checkedPaths[entry.Path] {
+			entry.Count = min(count, entry.Count)
+			remaining[entry.key()] -= entry.Count
+			staleEntries = append(staleEntries, entry)
+		}
+	}
+
+	return newErrors, staleEntries
+}
+
+// PrintStaleEntries reports the baseline entries which no longer occur, so they can be removed
+func PrintStaleEntries(staleEntries []Entry, config config.Config) {
+	if len(staleEntries) == 0 {
+		return
+	}
+
+	// the codeclimate format is json, which must not be interrupted by messages
+	report := config.Logger.Warning
+	if config.Format == outputformat.Codeclimate {
+		report = config.Logger.Verbose
+	}
+
+	report("%d baseline entries no longer occur, consider updating the baseline:", len(staleEntries))
+	for _, entry := range staleEntries {
+		report("\t%s: %s (%d times)", entry.Path, entry.Message, entry.Count)
+	}
+}
@@ -0,0 +1,94 @@
+package baseline
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	// x-release-please-start-major
+	eccerror "github.com/editorconfig-checker/editorconfig-checker/v3/pkg/error"
+	// x-release-please-end
+)
+
+func trailingWhitespace(lineNumber int, line string) eccerror.ValidationError {
+	return eccerror.ValidationError{LineNumber: lineNumber, Message: errors.New("Trailing whitespace"), Rule: "trim-trailing-whitespace", LineHash: eccerror.HashLine(line)}
+}
+
+func finalNewline() eccerror.ValidationError {
+	return eccerror.ValidationError{LineNumber: -1, Message: errors.New("Final newline expected"), Rule: "insert-final-newline"}
+}
+
+func TestNew(t *testing.T) {
+	baseline := New([]eccerror.ValidationErrors{
+		{FilePath: "b.txt", Errors: []eccerror.ValidationError{finalNewline()}},
+		{FilePath: "a.txt", Errors: []eccerror.ValidationError{trailingWhitespace(1, "x "), trailingWhitespace(5, "x "), trailingWhitespace(7, "y ")}},
+		{FilePath: "c.txt"},
+	})
+
+	expected := []Entry{
+		{Path: "a.txt", Rule: "trim-trailing-whitespace", LineHash: eccerror.HashLine("x "), Message: "Trailing whitespace", Count: 2},
+		{Path: "a.txt", Rule: "trim-trailing-whitespace", LineHash: eccerror.HashLine("y "), Message: "Trailing whitespace", Count: 1},
+		{Path: "b.txt", Rule: "insert-final-newline", Message: "Final newline expected", Count: 1},
+	}
+	if expected[0].LineHash > expected[1].LineHash {
+		expected[0], expected[1] = expected[1], expected[0]
+	}
+
+	if baseline.Version != Version {
+		t.Errorf("expected version %d, got %d", Version, baseline.Version)
+	}
+	if !reflect.DeepEqual(baseline.Entries, expected) {
+		t.Errorf("expected entries\n%+v\ngot\n%+v", expected, baseline.Entries)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	baseline := New([]eccerror.ValidationErrors{
+		{FilePath: "a.txt", Errors: []eccerror.ValidationError{trailingWhitespace(1, "x "), trailingWhitespace(5, "x "), finalNewline()}},
+		{FilePath: "b.txt", Errors: []eccerror.ValidationError{finalNewline()}},
+		{FilePath: "unchecked.txt", Errors: []eccerror.ValidationError{finalNewline()}},
+	})
+
+	// lines were inserted in front, one of the recorded errors was fixed and a new one was introduced
+	newErrors, staleEntries := baseline.Filter([]eccerror.ValidationErrors{
+		{FilePath: "a.txt", Errors: []eccerror.ValidationError{trailingWhitespace(3, "x "), trailingWhitespace(10, "z ")}},
+		{FilePath: "b.txt", Errors: []eccerror.ValidationError{finalNewline()}},
+	})
+
+	if eccerror.GetErrorCount(newErrors) != 1 || newErrors[0].Errors[0].LineNumber != 10 {
+		t.Errorf("expected only the error on line 10 to be new, got %+v", newErrors)
+	}
+
+	if len(staleEntries) != 2 {
+		t.Fatalf("expected two stale entries, got %+v", staleEntries)
+	}
+	for _, entry := range staleEntries {
+		if entry.Path != "a.txt" || entry.Count != 1 {
+			t.Errorf("expected single stale entries of a.txt, got %+v", entry)
+		}
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	baseline := New([]eccerror.ValidationErrors{
+		{FilePath: "a.txt", Errors: []eccerror.ValidationError{trailingWhitespace(1, "x "), finalNewline()}},
+	})
+
+	if err := baseline.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, baseline) {
+		t.Errorf("expected\n%+v\ngot\n%+v", baseline, loaded)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "nonexistent.json")); err == nil {
+		t.Error("expected an error loading a nonexistent baseline")
+	}
+}
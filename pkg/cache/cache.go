@@ -0,0 +1,230 @@
+// Package cache stores the validation errors of files across runs, so unchanged files are not validated again
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultDir is the directory the cache is stored in, relative to the working directory
+const DefaultDir = ".cache/editorconfig-checker"
+
+// Version is the version of the cache file format
+const Version = 1
+
+// fileName is the name of the cache file inside of the cache directory
+const fileName = "results.json"
+
+// Error is a validation error as it is stored in the cache
+type Error struct {
+	LineNumber int
+	Message    string
+	Rule       string
+	LineHash   string `json:",omitempty"`
+	Severity   string `json:",omitempty"`
+}
+
+// Entry holds the errors of a file, which are valid as long as the key of the file does not change
+type Entry struct {
+	Key string
+	// Stamp identifies the file on the file system by its size and modification time,
+	// so it is found without reading the file as long as it was not modified
+	Stamp  string `json:",omitempty"`
+	Errors []Error
+}
+
+// file is the content of the cache file
+type file struct {
+	Version int
+	Entries map[string]Entry
+}
+
+// Cache holds the errors of the files of the previous runs by their path
+type Cache struct {
+	dir string
+	// salt is part of every key, so a change of it invalidates all entries
+	salt    string
+	lock    sync.Mutex
+	entries map[string]Entry
+	// used are the paths which were looked up or stored in this run
+	used map[string]bool
+}
+
+// Load reads the cache from the directory.
+// The salt identifies everything the errors depend on besides the content and the properties of a file,
+// like the version of editorconfig-checker and its configuration.
+// A cache which does not exist or cannot be read is treated as empty.
+func Load(dir string, salt string) *Cache {
+	cache := &Cache{dir: dir, salt: salt, entries: make(map[string]Entry), used: make(map[string]bool)}
+
+	content, err := os.ReadFile(filepath.Join(dir, fileName))
+	if err != nil {
+		return cache
+	}
+	var cached file
+	if err := json.Unmarshal(content, &cached); err != nil || cached.Version != Version || cached.Entries == nil {
+		return cache
+	}
+	cache.entries = cached.Entries
+
+	return cache
+}
+
+// KeyHash computes the key of a file from the content written to it,
+// so the key is computed while the content is read for the validation
+type KeyHash struct {
+	hash hash.Hash
+}
+
+// Write adds content of the file to the key
+func (h *KeyHash) Write(content []byte) (int, error) {
+	return h.hash.Write(content)
+}
+
+// Key returns the key of the file with the content written so far
+func (h *KeyHash) Key() string {
+	return hex.EncodeToString(h.hash.Sum(nil))
+}
+
+// NewKeyHash returns the KeyHash of a file with the resolved .editorconfig properties, to which its content is to be written
+func (c *Cache) NewKeyHash(properties map[string]string) *KeyHash {
+	return &KeyHash{hash: c.hash(properties)}
+}
+
+// Key returns the key of a file with the content and the resolved .editorconfig properties
+func (c *Cache) Key(content io.Reader, properties map[string]string) (string, error) {
+	keyHash := c.NewKeyHash(properties)
+	if _, err := io.Copy(keyHash, content); err != nil {
+		return "", err
+	}
+	return keyHash.Key(), nil
+}
+
+// Stamp returns the stamp of a file on the file system with its size, modification time and the resolved .editorconfig properties
+func (c *Cache) Stamp(size int64, modTime time.Time, properties map[string]string) string {
+	hash := c.hash(properties)
+	hash.Write(binary.BigEndian.AppendUint64(nil, uint64(size)))
+	hash.Write(binary.BigEndian.AppendUint64(nil, uint64(modTime.UnixNano())))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// hash returns a hash of the salt and the properties, to which the content or the stamp of a file is added
+func (c *Cache) hash(properties map[string]string) hash.Hash {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	// the lengths separate the parts, so no two different inputs hash the same data
+	writeString := func(value string) {
+		hash.Write(binary.BigEndian.AppendUint32(nil, uint32(len(value))))
+		hash.Write([]byte(value))
+	}
+	writeString(c.salt)
+	for _, name := range names {
+		writeString(name)
+		writeString(properties[name])
+	}
+	return hash
+}
+
+// Get returns the errors of a file if they were stored with the same key
+func (c *Cache) Get(filePath string, key string) ([]Error, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.used[filePath] = true
+	entry, ok := c.entries[filePath]
+	if !ok || entry.Key != key {
+		return nil, false
+	}
+	return entry.Errors, true
+}
+
+// GetStamped returns the errors of a file if they were stored with the same stamp
+func (c *Cache) GetStamped(filePath string, stamp string) ([]Error, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.used[filePath] = true
+	entry, ok := c.entries[filePath]
+	if !ok || entry.Stamp == "" || entry.Stamp != stamp {
+		return nil, false
+	}
+	return entry.Errors, true
+}
+
+// Put stores the errors of a file with its key
+func (c *Cache) Put(filePath string, key string, errors []Error) {
+	c.PutStamped(filePath, key, "", errors)
+}
+
+// PutStamped stores the errors of a file on the file system with its key and its stamp
+func (c *Cache) PutStamped(filePath string, key string, stamp string, errors []Error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.used[filePath] = true
+	c.entries[filePath] = Entry{Key: key, Stamp: stamp, Errors: errors}
+}
+
+// Save writes the cache to its directory.
+// Entries of files which were not checked in this run are kept as long as the files exist.
+func (c *Cache) Save() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for filePath := range c.entries {
+		if c.used[filePath] {
+			continue
+		}
+		if _, err := os.Lstat(filePath); errors.Is(err, fs.ErrNotExist) {
+			delete(c.entries, filePath)
+		}
+	}
+
+	content, err := json.Marshal(file{Version: Version, Entries: c.entries})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+	// the cache is neither to be committed nor to be checked
+	gitignorePath := filepath.Join(c.dir, ".gitignore")
+	if _, err := os.Stat(gitignorePath); errors.Is(err, fs.ErrNotExist) {
+		if err := os.WriteFile(gitignorePath, []byte("*\n"), 0o644); err != nil {
+			return err
+		}
+	}
+
+	// write to a temporary file first, so concurrent runs never read a partially written cache
+	temporaryFile, err := os.CreateTemp(c.dir, fileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporaryFile.Name())
+	if _, err := temporaryFile.Write(append(content, '\n')); err != nil {
+		temporaryFile.Close()
+		return err
+	}
+	if err := temporaryFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temporaryFile.Name(), filepath.Join(c.dir, fileName))
+}
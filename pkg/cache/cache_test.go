@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	cache := Load(t.TempDir(), "v1")
+	properties := map[string]string{"indent_style": "tab", "indent_size": "4"}
+
+	key := func(cache *Cache, content string, properties map[string]string) string {
+		t.Helper()
+		key, err := cache.Key(strings.NewReader(content), properties)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+
+	base := key(cache, "content\n", properties)
+	if key(cache, "content\n", map[string]string{"indent_size": "4", "indent_style": "tab"}) != base {
+		t.Error("expected the key to be independent of the order of the properties")
+	}
+	for name, other := range map[string]string{
+		"content":    key(cache, "changed\n", properties),
+		"properties": key(cache, "content\n", map[string]string{"indent_style": "space", "indent_size": "4"}),
+		"salt":       key(Load(t.TempDir(), "v2"), "content\n", properties),
+	} {
+		if other == base {
+			t.Errorf("expected a changed %s to change the key", name)
+		}
+	}
+}
+
+func TestStamp(t *testing.T) {
+	cache := Load(t.TempDir(), "v1")
+	properties := map[string]string{"indent_style": "tab"}
+	modTime := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	// the key written while the content is read matches the key of the whole content
+	keyHash := cache.NewKeyHash(properties)
+	if _, err := io.Copy(keyHash, strings.NewReader("content\n")); err != nil {
+		t.Fatal(err)
+	}
+	if key, err := cache.Key(strings.NewReader("content\n"), properties); err != nil || keyHash.Key() != key {
+		t.Errorf("expected the KeyHash to return %q, got %q, %v", key, keyHash.Key(), err)
+	}
+
+	base := cache.Stamp(8, modTime, properties)
+	for name, other := range map[string]string{
+		"size":       cache.Stamp(9, modTime, properties),
+		"time":       cache.Stamp(8, modTime.Add(time.Nanosecond), properties),
+		"properties": cache.Stamp(8, modTime, map[string]string{"indent_style": "space"}),
+		"salt":       Load(t.TempDir(), "v2").Stamp(8, modTime, properties),
+	} {
+		if other == base {
+			t.Errorf("expected a changed %s to change the stamp", name)
+		}
+	}
+
+	// an entry is found by its stamp only if it was stored with one
+	cache.Put("unstamped.txt", "key", nil)
+	cache.PutStamped("stamped.txt", "key", base, nil)
+	if _, ok := cache.GetStamped("unstamped.txt", ""); ok {
+		t.Error("expected an entry without a stamp not to be found by its stamp")
+	}
+	if _, ok := cache.GetStamped("stamped.txt", base); !ok {
+		t.Error("expected the entry to be found by its stamp")
+	}
+	if _, ok := cache.Get("stamped.txt", "key"); !ok {
+		t.Error("expected the stamped entry to be found by its key")
+	}
+}
+
+func TestSave(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("kept.txt", nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	errors := []Error{{LineNumber: 1, Message: "Trailing whitespace", Rule: "trim-trailing-whitespace", LineHash: "hash"}}
+	cache := Load("cache", "v1")
+	cache.Put("kept.txt", "key", errors)
+	cache.Put("deleted.txt", "key", nil)
+	cache.Put("checked.txt", "key", nil)
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join("cache", ".gitignore")); err != nil {
+		t.Errorf("expected the cache to be ignored by git: %v", err)
+	}
+
+	// only the entries of the files checked in the last run or still existing are kept
+	cache = Load("cache", "v1")
+	if _, ok := cache.Get("checked.txt", "key"); !ok {
+		t.Error("expected the entry of checked.txt to be loaded")
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	cache = Load("cache", "v1")
+	if cached, ok := cache.Get("kept.txt", "key"); !ok || !reflect.DeepEqual(cached, errors) {
+		t.Errorf("expected the errors of kept.txt, got %v, %v", cached, ok)
+	}
+	if _, ok := cache.Get("kept.txt", "other key"); ok {
+		t.Error("expected no errors for a different key")
+	}
+	if _, ok := cache.Get("deleted.txt", "key"); ok {
+		t.Error("expected the entry of the deleted file to be removed")
+	}
+	// like a file inside of an archive, a file checked in the previous run is kept without existing on disk
+	if _, ok := cache.Get("checked.txt", "key"); !ok {
+		t.Error("expected the entry of checked.txt to be kept")
+	}
+}
@@ -0,0 +1,205 @@
+// Package checker checks files against their .editorconfig from other Go programs,
+// without the global state and the output of the command line tool
+package checker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	eccerror "github.com/editorconfig-checker/editorconfig-checker/v3/pkg/error"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/events"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/files"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/logger"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/run"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/source"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/validation"
+	// x-release-please-end
+)
+
+// DefaultConfigFileName is the config file which is read if no other one is given, if it exists
+const DefaultConfigFileName = ".editorconfig-checker.json"
+
+// options holds what a Checker is built from
+type options struct {
+	configFilePath string
+	configs        []config.Config
+	logger         *logger.Logger
+	source         source.Source
+	subscriber     events.Subscriber
+}
+
+// Option changes how a Checker checks files
+type Option func(*options)
+
+// WithConfigFile reads the config file at the given path, which has to exist,
+// instead of the .editorconfig-checker.json of the working directory
+func WithConfigFile(filePath string) Option {
+	return func(o *options) {
+		o.configFilePath = filePath
+	}
+}
+
+// WithConfig applies the settings of the given config on top of the config file,
+// like the command line arguments do
+func WithConfig(config config.Config) Option {
+	return func(o *options) {
+		o.configs = append(o.configs, config)
+	}
+}
+
+// WithLogger prints the warnings and, if enabled, the verbose and debug messages to the given logger.
+// Without it nothing is printed.
+func WithLogger(logger *logger.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithFS checks the files of the fs.FS with its .editorconfig files instead of the ones of the file system,
+// as if the root of the fs.FS was the working directory.
+// The config file is still read from the file system.
+func WithFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.source = source.NewFS(fsys)
+	}
+}
+
+// WithSubscriber sends the events of every check to the subscriber, like the files found and the errors in each of them
+func WithSubscriber(subscriber events.Subscriber) Option {
+	return func(o *options) {
+		o.subscriber = subscriber
+	}
+}
+
+// Checker checks files against their .editorconfig
+type Checker struct {
+	config     config.Config
+	source     source.Source
+	subscriber events.Subscriber
+}
+
+// Result holds what a check found
+type Result struct {
+	// Files are the files which were checked
+	Files []string
+	// Errors are the errors of every checked file
+	Errors []eccerror.ValidationErrors
+	// FileErrors are the files which could not be checked completely,
+	// like ones whose .editorconfig cannot be loaded or whose content cannot be read or decoded
+	FileErrors []validation.FileError
+}
+
+// ErrorCount returns the number of errors found in all files which are not warnings
+func (r Result) ErrorCount() int {
+	return eccerror.GetFailingErrorCount(r.Errors)
+}
+
+// WarningCount returns the number of warnings found in all files
+func (r Result) WarningCount() int {
+	return eccerror.GetWarningCount(r.Errors)
+}
+
+// Failed returns whether errors were found which are not warnings or files could not be checked completely
+func (r Result) Failed() bool {
+	return r.ErrorCount() != 0 || len(r.FileErrors) != 0
+}
+
+// New returns a Checker built from the options
+func New(opts ...Option) (*Checker, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	configFilePath := o.configFilePath
+	if configFilePath == "" {
+		configFilePath = DefaultConfigFileName
+	}
+	checkerConfig := config.NewConfig([]string{configFilePath})
+	// the logger of the checker is configured by its config, so the logger of the caller is copied rather than changed
+	checkerConfig.Logger = logger.GetLogger()
+	if o.logger != nil {
+		checkerConfig.Logger.Configure(o.logger)
+	} else {
+		checkerConfig.Logger.SetWriter(io.Discard)
+	}
+
+	// a missing default config file means the defaults are used
+	if err := checkerConfig.Parse(); err != nil && !(o.configFilePath == "" && errors.Is(err, fs.ErrNotExist)) {
+		return nil, err
+	}
+	for _, overrides := range o.configs {
+		checkerConfig.Merge(overrides)
+	}
+	if err := validation.CheckRules(*checkerConfig); err != nil {
+		return nil, err
+	}
+	if _, err := checkerConfig.CachedExcludesAsRegexp(); err != nil {
+		return nil, err
+	}
+
+	return &Checker{config: *checkerConfig, source: o.source, subscriber: o.subscriber}, nil
+}
+
+// Check checks the given files and the files in the given directories, or all files tracked by git without paths,
+// honoring the excludes like the command line tool does.
+// It stops when the context is done and returns its error.
+// The .editorconfig files are read again on every check, and checks may run concurrently.
+func (c *Checker) Check(ctx context.Context, paths []string) (Result, error) {
+	checkConfig, state := c.newCheck()
+	checkConfig.PassedFiles = paths
+
+	discovered, err := files.DiscoverAll(ctx, checkConfig, state)
+	if err != nil {
+		return Result{}, err
+	}
+
+	validationErrors, fileErrors, err := validation.ProcessDiscoveredValidation(ctx, discovered, checkConfig, state)
+	if err != nil {
+		return Result{}, err
+	}
+
+	filePaths := make([]string, 0, len(discovered))
+	for _, file := range discovered {
+		filePaths = append(filePaths, file.Path)
+	}
+
+	return Result{Files: filePaths, Errors: validationErrors, FileErrors: fileErrors}, nil
+}
+
+// ValidateBytes checks the content as if it was the file at the path, like generated code before it is written.
+// The .editorconfig files which apply to the path are read, but the excludes do not apply to it.
+// The error is returned along with the errors found if the content cannot be decoded completely.
+func (c *Checker) ValidateBytes(filePath string, content []byte) ([]eccerror.ValidationError, error) {
+	checkConfig, _ := c.newCheck()
+
+	def, warnings, err := checkConfig.EditorconfigConfig.LoadGraceful(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if warnings != nil {
+		checkConfig.Logger.Warning("%v", warnings.Error())
+	}
+
+	return validation.ValidateContent(filePath, content, checkConfig, def)
+}
+
+// newCheck returns the config and the state of a single check, which reads the .editorconfig files and archives again
+func (c *Checker) newCheck() (config.Config, *run.State) {
+	checkConfig := c.config
+	state := run.New()
+	state.Source = c.source
+	state.Events = c.subscriber
+	if c.source != nil {
+		checkConfig.EditorconfigConfig = &editorconfig.Config{Parser: source.NewParser(c.source)}
+	} else {
+		state.ReloadEditorconfigs(&checkConfig)
+	}
+	return checkConfig, state
+}
@@ -0,0 +1,237 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/encoding"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/logger"
+	// x-release-please-end
+)
+
+func setupFiles(t *testing.T) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	for name, content := range map[string]string{
+		".editorconfig":              "root = true\n\n[*]\ntrim_trailing_whitespace = true\n",
+		".editorconfig-checker.json": `{"Exclude": ["excluded"]}`,
+		"valid.txt":                  "valid\n",
+		"invalid.txt":                "invalid \n",
+		"excluded.txt":               "excluded \n",
+	} {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	setupFiles(t)
+
+	checker, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := checker.Check(context.Background(), []string{"valid.txt", "invalid.txt", "excluded.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"valid.txt", "invalid.txt"}; !reflect.DeepEqual(result.Files, expected) {
+		t.Errorf("expected the files %v to be checked, got %v", expected, result.Files)
+	}
+	if result.ErrorCount() != 1 {
+		t.Errorf("expected 1 error, got %d", result.ErrorCount())
+	}
+
+	// the settings given to the checker apply on top of the config file
+	checker, err = New(WithConfig(config.Config{Disable: config.DisabledChecks{TrimTrailingWhitespace: true}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err = checker.Check(context.Background(), []string{"invalid.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ErrorCount() != 0 {
+		t.Errorf("expected no errors with the check disabled, got %d", result.ErrorCount())
+	}
+
+	// warnings are counted apart from the errors and do not fail the check
+	checker, err = New(WithConfig(config.Config{Rules: map[string]string{"trim-trailing-whitespace": config.SeverityWarning}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err = checker.Check(context.Background(), []string{"invalid.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ErrorCount() != 0 || result.WarningCount() != 1 || result.Failed() {
+		t.Errorf("expected 1 warning and no errors, got %d errors and %d warnings", result.ErrorCount(), result.WarningCount())
+	}
+}
+
+func TestCheckCancelled(t *testing.T) {
+	setupFiles(t)
+
+	checker, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := checker.Check(ctx, []string{"invalid.txt"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the check to be cancelled, got %v", err)
+	}
+}
+
+func TestCheckFileErrors(t *testing.T) {
+	setupFiles(t)
+	if err := os.Mkdir("broken", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"broken/.editorconfig": "[*\nindent_style = tab\n",
+		"broken/file.txt":      "file\n",
+	} {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checker, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := checker.Check(context.Background(), []string{"valid.txt", "broken/file.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.FileErrors) != 1 || result.FileErrors[0].FilePath != "broken/file.txt" {
+		t.Errorf("expected the .editorconfig of broken/file.txt not to be loaded, got %v", result.FileErrors)
+	}
+	if !result.Failed() {
+		t.Error("expected the check to fail when a file could not be checked")
+	}
+}
+
+func TestNew(t *testing.T) {
+	setupFiles(t)
+
+	if _, err := New(WithConfigFile("missing.json")); err == nil {
+		t.Error("expected an error for a missing config file")
+	}
+	if _, err := New(WithConfig(config.Config{Exclude: []string{"("}})); err == nil {
+		t.Error("expected an error for an invalid exclude")
+	}
+
+	// without a config file of its own the checker uses the defaults
+	if err := os.Remove(DefaultConfigFileName); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(); err != nil {
+		t.Errorf("expected no error without a config file, got %v", err)
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	setupFiles(t)
+
+	var output bytes.Buffer
+	callerLogger := logger.GetLogger()
+	callerLogger.SetWriter(&output)
+	verbose, err := New(WithLogger(callerLogger), WithConfig(config.Config{Verbose: true, NoColor: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	quiet, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	silent, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the logger of the caller is not changed by the config of the checker
+	if callerLogger.VerboseEnabled || callerLogger.NoColor {
+		t.Errorf("expected the logger of the caller not to be changed, got %+v", callerLogger)
+	}
+	if verbose.config.Logger == callerLogger || !verbose.config.Logger.VerboseEnabled {
+		t.Errorf("expected the checker to print the verbose messages to a copy of the logger of the caller")
+	}
+	// the checkers without a logger do not share one
+	if quiet.config.Logger == silent.config.Logger {
+		t.Error("expected every checker to have a logger of its own")
+	}
+
+	if _, err := verbose.Check(context.Background(), []string{"valid.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output.String(), "valid.txt") {
+		t.Errorf("expected the verbose messages to be written to the writer of the caller, got %q", output.String())
+	}
+}
+
+func TestCheckFS(t *testing.T) {
+	setupFiles(t)
+
+	checker, err := New(WithFS(fstest.MapFS{
+		".editorconfig":      {Data: []byte("root = true\n\n[*]\nindent_style = tab\n")},
+		"src/generated.go":   {Data: []byte("package src\n\nfunc f() {\n  return\n}\n")},
+		"src/excluded.go":    {Data: []byte("package src\n\n  \n")},
+		"other/untouched.go": {Data: []byte("  \n")},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the files and the .editorconfig on disk are not used, but the excludes of the config file still apply
+	result, err := checker.Check(context.Background(), []string{"src"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"src/generated.go"}; !reflect.DeepEqual(result.Files, expected) {
+		t.Errorf("expected the files %v to be checked, got %v", expected, result.Files)
+	}
+	if result.ErrorCount() != 1 {
+		t.Errorf("expected the wrong indentation to be found, got %v", result.Errors)
+	}
+}
+
+func TestValidateBytes(t *testing.T) {
+	setupFiles(t)
+
+	checker, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the excludes do not apply to content which is validated explicitly
+	for _, filePath := range []string{"valid.txt", "excluded.txt"} {
+		validationErrors, err := checker.ValidateBytes(filePath, []byte("trailing whitespace \n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(validationErrors) != 1 {
+			t.Errorf("ValidateBytes(%s): expected the trailing whitespace to be found, got %v", filePath, validationErrors)
+		}
+	}
+
+	// content which cannot be decoded is not reported as content without errors
+	content, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(strings.Repeat("中华人民共和国的首都是北京。这是一个测试文件的内容。\n", 5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decodeErr *encoding.UnrecogizedEncodingError
+	if _, err := checker.ValidateBytes("gb2312.txt", content); !errors.As(err, &decodeErr) {
+		t.Errorf("ValidateBytes(gb2312.txt): expected a decode error, got %v", err)
+	}
+}
@@ -2,21 +2,40 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"regexp"
+	"runtime"
 	"strings"
 
 	"github.com/editorconfig/editorconfig-core-go/v2"
 
 	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/gitignore"
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/logger"
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/outputformat"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/resolver"
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/utils"
 	// x-release-please-end
 )
 
+// IgnoreFileName is the name of the files with glob excludes for their directory
+const IgnoreFileName = ".ecignore"
+
+// The severities a rule can be set to in the Rules
+const (
+	// SeverityOff disables a rule
+	SeverityOff = "off"
+	// SeverityWarning reports the errors of a rule without failing the run
+	SeverityWarning = "warning"
+	// SeverityError reports the errors of a rule and fails the run
+	SeverityError = "error"
+)
+
 // DefaultExcludes is the regular expression for ignored files
 var DefaultExcludes = strings.Join(defaultExcludes, "|")
 
@@ -125,10 +144,24 @@ var defaultAllowedContentTypes = []string{
 // Config struct, contains everything a config can contain
 type Config struct {
 	// CLI
-	ShowVersion bool
-	Help        bool
-	DryRun      bool
-	Path        string
+	ShowVersion   bool
+	Help          bool
+	DryRun        bool
+	Path          string
+	BaselineWrite string
+	ChangedSince  string
+	MergeBase     string
+	NewLinesOnly  string
+	Staged        bool
+	Ref           string
+	Stdin         bool
+	StdinFilename string
+	NoCache       bool
+	CacheDir      string
+	Watch         bool
+	Progress      bool
+	Unordered     bool
+	Jobs          int
 
 	// CONFIG FILE
 	Version             string
@@ -139,9 +172,20 @@ type Config struct {
 	SpacesAfterTabs     bool
 	NoColor             bool
 	Exclude             []string
+	ExcludeGlobs        []string
 	AllowedContentTypes []string
 	PassedFiles         []string
 	Disable             DisabledChecks
+	Baseline            string
+	NoGit               bool
+	// MaxFileSize is the size in bytes above which files are skipped, or truncated if LargeFileLines is set
+	MaxFileSize int64
+	// LargeFileLines is the number of lines which are checked of files larger than the MaxFileSize
+	LargeFileLines int
+	// Rules sets the rules by their ID to SeverityOff, SeverityWarning or SeverityError
+	Rules map[string]string
+	// CustomRules are rules which report the lines matching a regular expression
+	CustomRules []CustomRule
 
 	// MISC
 	Logger             *logger.Logger
@@ -149,8 +193,35 @@ type Config struct {
 
 	// CACHE
 	excludeRegexp *regexp.Regexp
+	excludeGlobs  *gitignore.Matcher
+	customRules   []CompiledCustomRule
+}
+
+// CustomRule is a rule of the config file which reports the lines matching a regular expression
+type CustomRule struct {
+	// ID names the rule in the errors and the Rules
+	ID string
+	// Regex is the regular expression the lines are reported for
+	Regex string
+	// Message is the message of the errors
+	Message string
+	// Severity is SeverityWarning or SeverityError, which is the default
+	Severity string
+	// Files are gitignore-style glob patterns of the files the rule applies to, all files if empty
+	Files []string
 }
 
+// CompiledCustomRule is a CustomRule with its regular expression and glob patterns compiled
+type CompiledCustomRule struct {
+	CustomRule
+	Regexp *regexp.Regexp
+	// FilesMatcher matches the Files, it is nil if the rule applies to all files
+	FilesMatcher *gitignore.Matcher
+}
+
+// IndentSizeCheck disables only the check of the indent_size of the indentation rule in the DisabledChecks
+const IndentSizeCheck = "indent-size"
+
 // DisabledChecks is a Struct which represents disabled checks
 type DisabledChecks struct {
 	Charset                bool
@@ -160,6 +231,115 @@ type DisabledChecks struct {
 	TrimTrailingWhitespace bool
 	IndentSize             bool
 	MaxLineLength          bool
+	// Rules disables any rule by its ID, or only the check of the indent_size with the IndentSizeCheck
+	Rules map[string]bool
+}
+
+// legacyDisabledChecks are the IDs of the checks by the lowercased names of the fields they have besides the Rules
+var legacyDisabledChecks = map[string]string{
+	"charset":                "charset",
+	"endofline":              "end-of-line",
+	"indentation":            "indentation",
+	"insertfinalnewline":     "insert-final-newline",
+	"trimtrailingwhitespace": "trim-trailing-whitespace",
+	"indentsize":             IndentSizeCheck,
+	"maxlinelength":          "max-line-length",
+}
+
+// IsDisabled returns whether the rule with the ID, or the IndentSizeCheck, is disabled,
+// by the Rules or by the field of the check
+func (d DisabledChecks) IsDisabled(id string) bool {
+	if d.Rules[id] {
+		return true
+	}
+	switch id {
+	case "charset":
+		return d.Charset
+	case "end-of-line":
+		return d.EndOfLine
+	case "indentation":
+		return d.Indentation
+	case "insert-final-newline":
+		return d.InsertFinalNewline
+	case "trim-trailing-whitespace":
+		return d.TrimTrailingWhitespace
+	case IndentSizeCheck:
+		return d.IndentSize
+	case "max-line-length":
+		return d.MaxLineLength
+	}
+	return false
+}
+
+// legacyRules returns the checks disabled by the fields by their ID
+func (d DisabledChecks) legacyRules() map[string]bool {
+	return map[string]bool{
+		"charset":                  d.Charset,
+		"end-of-line":              d.EndOfLine,
+		"indentation":              d.Indentation,
+		"insert-final-newline":     d.InsertFinalNewline,
+		"trim-trailing-whitespace": d.TrimTrailingWhitespace,
+		IndentSizeCheck:            d.IndentSize,
+		"max-line-length":          d.MaxLineLength,
+	}
+}
+
+// disable disables a check by its ID, which sets the field of the check as well
+func (d *DisabledChecks) disable(id string) {
+	switch id {
+	case "charset":
+		d.Charset = true
+	case "end-of-line":
+		d.EndOfLine = true
+	case "indentation":
+		d.Indentation = true
+	case "insert-final-newline":
+		d.InsertFinalNewline = true
+	case "trim-trailing-whitespace":
+		d.TrimTrailingWhitespace = true
+	case IndentSizeCheck:
+		d.IndentSize = true
+	case "max-line-length":
+		d.MaxLineLength = true
+	}
+	if d.Rules == nil {
+		d.Rules = make(map[string]bool)
+	}
+	d.Rules[id] = true
+}
+
+// UnmarshalJSON reads the disabled checks by their ID, or case-insensitively by the names of their fields
+func (d *DisabledChecks) UnmarshalJSON(data []byte) error {
+	var checks map[string]bool
+	if err := json.Unmarshal(data, &checks); err != nil {
+		return err
+	}
+	*d = DisabledChecks{}
+	for name, disabled := range checks {
+		if id, ok := legacyDisabledChecks[strings.ToLower(name)]; ok {
+			name = id
+		}
+		if disabled {
+			d.disable(name)
+		}
+	}
+	return nil
+}
+
+// MarshalJSON writes the disabled checks by their ID, whether they are disabled by the Rules or by their fields
+func (d DisabledChecks) MarshalJSON() ([]byte, error) {
+	checks := make(map[string]bool)
+	for id, disabled := range d.legacyRules() {
+		if disabled {
+			checks[id] = true
+		}
+	}
+	for id, disabled := range d.Rules {
+		if disabled {
+			checks[id] = true
+		}
+	}
+	return json.Marshal(checks)
 }
 
 // NewConfig initializes a new config
@@ -171,7 +351,7 @@ func NewConfig(configPaths []string) *Config {
 	config.PassedFiles = []string{}
 
 	config.EditorconfigConfig = &editorconfig.Config{
-		Parser: editorconfig.NewCachedParser(),
+		Parser: resolver.NewParser(resolver.FileSystem{}),
 	}
 
 	var configPath string = ""
@@ -263,6 +443,10 @@ func (c *Config) Merge(config Config) {
 		c.Exclude = append(c.Exclude, config.Exclude...)
 	}
 
+	if len(config.ExcludeGlobs) != 0 {
+		c.ExcludeGlobs = append(c.ExcludeGlobs, config.ExcludeGlobs...)
+	}
+
 	if len(config.AllowedContentTypes) != 0 {
 		c.AllowedContentTypes = append(c.AllowedContentTypes, config.AllowedContentTypes...)
 	}
@@ -271,48 +455,117 @@ func (c *Config) Merge(config Config) {
 		c.PassedFiles = config.PassedFiles
 	}
 
-	c.mergeDisabled(config.Disable)
+	if config.Baseline != "" {
+		c.Baseline = config.Baseline
+	}
 
-	if c.Logger == nil {
-		c.Logger = logger.GetLogger()
+	if config.NoGit {
+		c.NoGit = config.NoGit
 	}
-	c.Logger.Configure(&logger.Logger{
-		VerboseEnabled: c.Verbose || config.Verbose,
-		DebugEnabled:   c.Debug || config.Debug,
-		NoColor:        c.NoColor || config.NoColor,
-	})
-}
 
-// mergeDisabled merges the disabled checks into the config
-// This is here because cyclomatic complexity of gocyclo was about 15 :/
-func (c *Config) mergeDisabled(disabled DisabledChecks) {
-	if disabled.EndOfLine {
-		c.Disable.EndOfLine = disabled.EndOfLine
+	if config.MaxFileSize != 0 {
+		c.MaxFileSize = config.MaxFileSize
+	}
+
+	if config.LargeFileLines != 0 {
+		c.LargeFileLines = config.LargeFileLines
+	}
+
+	if len(config.CustomRules) != 0 {
+		c.CustomRules = append(c.CustomRules, config.CustomRules...)
+	}
+
+	for rule, severity := range config.Rules {
+		if c.Rules == nil {
+			c.Rules = make(map[string]string, len(config.Rules))
+		}
+		c.Rules[rule] = severity
+	}
+
+	if config.BaselineWrite != "" {
+		c.BaselineWrite = config.BaselineWrite
+	}
+
+	if config.ChangedSince != "" {
+		c.ChangedSince = config.ChangedSince
+	}
+
+	if config.MergeBase != "" {
+		c.MergeBase = config.MergeBase
+	}
+
+	if config.NewLinesOnly != "" {
+		c.NewLinesOnly = config.NewLinesOnly
+	}
+
+	if config.Staged {
+		c.Staged = config.Staged
+	}
+
+	if config.Ref != "" {
+		c.Ref = config.Ref
 	}
 
-	if disabled.TrimTrailingWhitespace {
-		c.Disable.TrimTrailingWhitespace = disabled.TrimTrailingWhitespace
+	if config.Stdin {
+		c.Stdin = config.Stdin
 	}
 
-	if disabled.InsertFinalNewline {
-		c.Disable.InsertFinalNewline = disabled.InsertFinalNewline
+	if config.StdinFilename != "" {
+		c.StdinFilename = config.StdinFilename
 	}
 
-	if disabled.Indentation {
-		c.Disable.Indentation = disabled.Indentation
+	if config.NoCache {
+		c.NoCache = config.NoCache
 	}
 
-	if disabled.IndentSize {
-		c.Disable.IndentSize = disabled.IndentSize
+	if config.CacheDir != "" {
+		c.CacheDir = config.CacheDir
 	}
 
-	if disabled.MaxLineLength {
-		c.Disable.MaxLineLength = disabled.MaxLineLength
+	if config.Watch {
+		c.Watch = config.Watch
 	}
 
-	if disabled.Charset {
-		c.Disable.Charset = disabled.Charset
+	if config.Progress {
+		c.Progress = config.Progress
 	}
+
+	if config.Unordered {
+		c.Unordered = config.Unordered
+	}
+
+	if config.Jobs != 0 {
+		c.Jobs = config.Jobs
+	}
+
+	c.mergeDisabled(config.Disable)
+
+	if c.Logger == nil {
+		c.Logger = logger.GetLogger()
+	}
+	c.Logger.Configure(&logger.Logger{
+		VerboseEnabled: c.Verbose || config.Verbose,
+		DebugEnabled:   c.Debug || config.Debug,
+		NoColor:        c.NoColor || config.NoColor,
+	})
+}
+
+// mergeDisabled merges the disabled checks into the config, the fields of the checks are mapped onto their rules.
+// The Rules are merged into a copy, as they may be shared with other copies of the config.
+func (c *Config) mergeDisabled(disabled DisabledChecks) {
+	merged := c.Disable
+	merged.Rules = maps.Clone(c.Disable.Rules)
+	for id, isDisabled := range disabled.legacyRules() {
+		if isDisabled {
+			merged.disable(id)
+		}
+	}
+	for id, isDisabled := range disabled.Rules {
+		if isDisabled {
+			merged.disable(id)
+		}
+	}
+	c.Disable = merged
 }
 
 // GetExcludesAsRegularExpression returns the excludes as a combined regular expression
@@ -338,6 +591,67 @@ func (c *Config) CachedExcludesAsRegexp() (*regexp.Regexp, error) {
 	return c.excludeRegexp, nil
 }
 
+// CachedExcludeGlobs returns the matcher of the glob excludes
+// The matcher is cached
+// Note: This is not thread-safe
+func (c *Config) CachedExcludeGlobs() *gitignore.Matcher {
+	if c.excludeGlobs == nil {
+		c.excludeGlobs = &gitignore.Matcher{}
+		c.excludeGlobs.AddPatterns([]byte(strings.Join(c.ExcludeGlobs, "\n")), "")
+	}
+	return c.excludeGlobs
+}
+
+// CachedCustomRules returns the CustomRules with their regular expressions and glob patterns compiled
+// The compilation is cached
+// Note: This is not thread-safe
+func (c *Config) CachedCustomRules() ([]CompiledCustomRule, error) {
+	if c.customRules == nil && len(c.CustomRules) != 0 {
+		customRules := make([]CompiledCustomRule, 0, len(c.CustomRules))
+		for _, customRule := range c.CustomRules {
+			if customRule.ID == "" {
+				return nil, fmt.Errorf("the custom rule with the regex %q has no ID", customRule.Regex)
+			}
+			re, err := regexp.Compile(customRule.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("custom rule %s: %w", customRule.ID, err)
+			}
+			compiled := CompiledCustomRule{CustomRule: customRule, Regexp: re}
+			if len(customRule.Files) != 0 {
+				compiled.FilesMatcher = &gitignore.Matcher{}
+				compiled.FilesMatcher.AddPatterns([]byte(strings.Join(customRule.Files, "\n")), "")
+			}
+			customRules = append(customRules, compiled)
+		}
+		c.customRules = customRules
+	}
+	return c.customRules, nil
+}
+
+// Workers returns the number of files which are processed at once in every stage of a run,
+// the Jobs if set or the number of CPUs
+func (c Config) Workers() int {
+	if c.Jobs > 0 {
+		return c.Jobs
+	}
+	return runtime.NumCPU()
+}
+
+// ValidationHash identifies the settings which change the errors found in a file of a given content,
+// including the limits which decide whether only the first lines of a file are checked
+func (c Config) ValidationHash() string {
+	settings, _ := json.Marshal(struct {
+		SpacesAfterTabs bool
+		Disable         DisabledChecks
+		Rules           map[string]string
+		CustomRules     []CustomRule
+		MaxFileSize     int64
+		LargeFileLines  int
+	}{c.SpacesAfterTabs, c.Disable, c.Rules, c.CustomRules, c.MaxFileSize, c.LargeFileLines})
+	sum := sha256.Sum256(settings)
+	return hex.EncodeToString(sum[:])
+}
+
 // Save saves the config to it's Path
 func (c Config) Save(version string) error {
 	if utils.IsRegularFile(c.Path) {
@@ -353,12 +667,19 @@ func (c Config) Save(version string) error {
 		SpacesAfterTabs     bool
 		NoColor             bool
 		Exclude             []string
+		ExcludeGlobs        []string
 		AllowedContentTypes []string
 		PassedFiles         []string
 		Disable             DisabledChecks
+		Baseline            string
+		NoGit               bool
+		MaxFileSize         int64
+		LargeFileLines      int
+		Rules               map[string]string
+		CustomRules         []CustomRule
 	}
 
-	configJSON, _ := json.MarshalIndent(writtenConfig{Version: version}, "", "  ")
+	configJSON, _ := json.MarshalIndent(writtenConfig{Version: version, Disable: DisabledChecks{}, Rules: map[string]string{}}, "", "  ")
 	configString := strings.Replace(string(configJSON[:]), "null", "[]", -1)
 	err := os.WriteFile(c.Path, []byte(configString), 0o644)
 
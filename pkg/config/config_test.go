@@ -1,10 +1,12 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"runtime"
 	"testing"
 
 	// x-release-please-start-major
@@ -153,6 +155,16 @@ func TestMerge(t *testing.T) {
 	expected.Logger.DebugEnabled = true
 	expected.Logger.NoColor = true
 	expected.EditorconfigConfig = modifiedConfig.EditorconfigConfig
+	// the fields of the disabled checks are mapped onto their rules
+	expected.Disable.Rules = map[string]bool{
+		"trim-trailing-whitespace": true,
+		"end-of-line":              true,
+		"insert-final-newline":     true,
+		"indentation":              true,
+		IndentSizeCheck:            true,
+		"max-line-length":          true,
+		"charset":                  true,
+	}
 
 	if !reflect.DeepEqual(modifiedConfig, &expected) {
 		t.Errorf("%#v", &expected)
@@ -185,16 +197,61 @@ func TestParse(t *testing.T) {
 		!reflect.DeepEqual(c.Exclude, []string{"testfiles"}) ||
 		!reflect.DeepEqual(c.AllowedContentTypes, []string{"text/", "application/octet-stream", "application/ecmascript", "application/json", "application/x-ndjson", "application/xml", "+json", "+xml", "hey"}) ||
 		c.SpacesAfterTabs != true ||
-		c.Disable.EndOfLine != false ||
-		c.Disable.TrimTrailingWhitespace != false ||
-		c.Disable.InsertFinalNewline != false ||
-		c.Disable.Indentation != false ||
-		c.Disable.Charset != false {
+		!reflect.DeepEqual(c.Disable, DisabledChecks{}) {
 		t.Error(c.AllowedContentTypes)
 		t.Errorf("Expected config to have values from test file, got %v", c)
 	}
 }
 
+func TestParseDisabledChecks(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config")
+	// the checks are disabled by their rule ID, or by the names they had before, which are matched case-insensitively
+	content := `{"Disable": {"max-line-length": true, "trimTrailingWhitespace": true, "IndentSize": true, "Charset": false}}`
+	if err := os.WriteFile(configFile, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewConfig([]string{configFile})
+	if err := c.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	expected := DisabledChecks{
+		TrimTrailingWhitespace: true,
+		IndentSize:             true,
+		MaxLineLength:          true,
+		Rules:                  map[string]bool{"max-line-length": true, "trim-trailing-whitespace": true, IndentSizeCheck: true},
+	}
+	if !reflect.DeepEqual(c.Disable, expected) {
+		t.Errorf("Parse: expected the disabled checks %v, got %v", expected, c.Disable)
+	}
+}
+
+func TestDisabledChecks(t *testing.T) {
+	// the fields of the checks and the Rules disable the same rules
+	for _, disabled := range []DisabledChecks{
+		{TrimTrailingWhitespace: true, IndentSize: true},
+		{Rules: map[string]bool{"trim-trailing-whitespace": true, IndentSizeCheck: true}},
+	} {
+		if !disabled.IsDisabled("trim-trailing-whitespace") || !disabled.IsDisabled(IndentSizeCheck) || disabled.IsDisabled("charset") {
+			t.Errorf("IsDisabled: expected only trim-trailing-whitespace and indent-size to be disabled by %+v", disabled)
+		}
+		written, err := json.Marshal(disabled)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := `{"indent-size":true,"trim-trailing-whitespace":true}`; string(written) != expected {
+			t.Errorf("MarshalJSON: expected %s, got %s", expected, written)
+		}
+	}
+
+	// a config built with the fields is merged into the rules
+	c := Config{}
+	c.Merge(Config{Disable: DisabledChecks{Charset: true, Rules: map[string]bool{"custom": true}}})
+	if !reflect.DeepEqual(c.Disable.Rules, map[string]bool{"charset": true, "custom": true}) || !c.Disable.Charset {
+		t.Errorf("Merge: expected charset and custom to be disabled, got %+v", c.Disable)
+	}
+}
+
 func TestSave(t *testing.T) {
 	dir, _ := os.MkdirTemp("", "example")
 	defer os.RemoveAll(dir)
@@ -245,3 +302,28 @@ func TestDefaultExcludesAnchoring(t *testing.T) {
 		}
 	}
 }
+
+func TestWorkers(t *testing.T) {
+	if workers := (Config{}).Workers(); workers != runtime.NumCPU() {
+		t.Errorf("expected the number of CPUs without Jobs, got %d", workers)
+	}
+	if workers := (Config{Jobs: 3}).Workers(); workers != 3 {
+		t.Errorf("expected the Jobs, got %d", workers)
+	}
+}
+
+func TestValidationHash(t *testing.T) {
+	base := Config{MaxFileSize: 100, LargeFileLines: 10}
+	if base.ValidationHash() != (Config{MaxFileSize: 100, LargeFileLines: 10, Verbose: true}).ValidationHash() {
+		t.Error("expected settings which do not change the errors to keep the hash")
+	}
+	for name, changed := range map[string]Config{
+		"SpacesAfterTabs": {MaxFileSize: 100, LargeFileLines: 10, SpacesAfterTabs: true},
+		"MaxFileSize":     {MaxFileSize: 200, LargeFileLines: 10},
+		"LargeFileLines":  {MaxFileSize: 100, LargeFileLines: 20},
+	} {
+		if base.ValidationHash() == changed.ValidationHash() {
+			t.Errorf("expected a changed %s to change the hash", name)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+// Package editorconfigfmt rewrites .editorconfig files into a canonical form
+package editorconfigfmt
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// knownProperties are the properties of the editorconfig specification in their canonical order,
+// their values are case insensitive
+var knownProperties = []string{
+	"indent_style",
+	"indent_size",
+	"tab_width",
+	"end_of_line",
+	"charset",
+	"spelling_language",
+	"trim_trailing_whitespace",
+	"insert_final_newline",
+	"max_line_length",
+}
+
+// caseSensitiveValues are known properties whose values must not be lowercased
+var caseSensitiveValues = []string{
+	"spelling_language",
+}
+
+// globsMatchingEverything are section globs which match every file
+var globsMatchingEverything = []string{"*", "**"}
+
+// Property is a key value pair together with the comments preceding it
+type Property struct {
+	Comments []string
+	Key      string
+	Value    string
+}
+
+// Section is a glob together with its properties and the comments preceding it
+type Section struct {
+	Comments   []string
+	Glob       string
+	Properties []Property
+}
+
+// File is a parsed .editorconfig file
+type File struct {
+	// Preamble holds the properties before the first section, like root
+	Preamble []Property
+	Sections []Section
+	// Comments holds the comments after the last property
+	Comments []string
+}
+
+// Parse parses the content of an .editorconfig file
+func Parse(content []byte) (File, error) {
+	var file File
+	var comments []string
+	var section *Section
+
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	for lineNumber, line := range lines {
+		line = strings.TrimSpace(line)
+		if lineNumber == 0 {
+			line = strings.TrimPrefix(line, "\uFEFF")
+		}
+
+		switch {
+		case line == "":
+			continue
+		case line[0] == '#' || line[0] == ';':
+			comments = append(comments, line)
+		case line[0] == '[':
+			if !strings.HasSuffix(line, "]") {
+				return File{}, fmt.Errorf("line %d: unterminated section header %q", lineNumber+1, line)
+			}
+			file.Sections = append(file.Sections, Section{Comments: comments, Glob: strings.TrimSpace(line[1 : len(line)-1])})
+			section = &file.Sections[len(file.Sections)-1]
+			comments = nil
+		default:
+			key, value, found := strings.Cut(line, "=")
+			if !found {
+				return File{}, fmt.Errorf("line %d: expected a key = value pair, got %q", lineNumber+1, line)
+			}
+			property := Property{Comments: comments, Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)}
+			comments = nil
+			if section == nil {
+				file.Preamble = append(file.Preamble, property)
+			} else {
+				section.Properties = append(section.Properties, property)
+			}
+		}
+	}
+	file.Comments = comments
+
+	return file, nil
+}
+
+// normalizeProperties lowercases the keys and known values, keeps only the last
+// occurrence of a key and orders the known properties first
+func normalizeProperties(properties []Property) []Property {
+	var normalized []Property
+	for _, property := range properties {
+		property.Key = strings.ToLower(property.Key)
+		if (slices.Contains(knownProperties, property.Key) || property.Key == "root") && !slices.Contains(caseSensitiveValues, property.Key) {
+			property.Value = strings.ToLower(property.Value)
+		}
+
+		if index := slices.IndexFunc(normalized, func(p Property) bool { return p.Key == property.Key }); index != -1 {
+			// a later occurrence of a key wins, so the earlier one is dropped but its comments are kept
+			property.Comments = append(normalized[index].Comments, property.Comments...)
+			normalized = slices.Delete(normalized, index, index+1)
+		}
+		normalized = append(normalized, property)
+	}
+
+	slices.SortStableFunc(normalized, func(a Property, b Property) int {
+		return propertyRank(a.Key) - propertyRank(b.Key)
+	})
+
+	return normalized
+}
+
+// propertyRank returns the position of a key in the canonical order, unknown keys come last
+func propertyRank(key string) int {
+	if index := slices.Index(knownProperties, key); index != -1 {
+		return index
+	}
+	return len(knownProperties)
+}
+
+// keys returns the set of keys a section sets
+func (s Section) keys() map[string]bool {
+	keys := make(map[string]bool, len(s.Properties))
+	for _, property := range s.Properties {
+		keys[property.Key] = true
+	}
+	return keys
+}
+
+// value returns the value a section sets for a key
+func (s Section) value(key string) (string, bool) {
+	for _, property := range s.Properties {
+		if property.Key == key {
+			return property.Value, true
+		}
+	}
+	return "", false
+}
+
+// commute returns whether two sections can be swapped without changing the result,
+// which is the case when they do not set any key in common
+func commute(a Section, b Section) bool {
+	aKeys := a.keys()
+	for key := range b.keys() {
+		if aKeys[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// sectionLess is the canonical order of sections: the ones matching everything first, then by glob
+func sectionLess(a Section, b Section) bool {
+	aEverything := slices.Contains(globsMatchingEverything, a.Glob)
+	bEverything := slices.Contains(globsMatchingEverything, b.Glob)
+	if aEverything != bEverything {
+		return aEverything
+	}
+	return a.Glob < b.Glob
+}
+
+// covers returns whether every file matched by glob b is matched by glob a as well
+func covers(a string, b string) bool {
+	return a == b || slices.Contains(globsMatchingEverything, a)
+}
+
+// mergeAdjacentSections merges directly following sections with the same glob
+func mergeAdjacentSections(sections []Section) []Section {
+	var merged []Section
+	for _, section := range sections {
+		if last := len(merged) - 1; last >= 0 && merged[last].Glob == section.Glob {
+			merged[last].Comments = append(merged[last].Comments, section.Comments...)
+			merged[last].Properties = append(merged[last].Properties, section.Properties...)
+			merged[last].Properties = normalizeProperties(merged[last].Properties)
+			continue
+		}
+		merged = append(merged, section)
+	}
+	return merged
+}
+
+// orderSections moves sections towards their canonical order,
+// but only past sections which they commute with
+func orderSections(sections []Section) {
+	for i := 1; i < len(sections); i++ {
+		for j := i; j > 0 && sectionLess(sections[j], sections[j-1]) && commute(sections[j], sections[j-1]); j-- {
+			sections[j], sections[j-1] = sections[j-1], sections[j]
+		}
+	}
+}
+
+// removeRedundantProperties drops properties which an earlier section matching all the
+// same files already sets to the same value, as long as no section in between sets that key
+func removeRedundantProperties(sections []Section) []Section {
+	var result []Section
+	for j, section := range sections {
+		var properties []Property
+		for _, property := range section.Properties {
+			redundant := false
+			for i := j - 1; i >= 0; i-- {
+				value, ok := sections[i].value(property.Key)
+				if !ok {
+					continue
+				}
+				redundant = value == property.Value && covers(sections[i].Glob, section.Glob)
+				break
+			}
+
+			if !redundant || len(property.Comments) > 0 {
+				properties = append(properties, property)
+			}
+		}
+		section.Properties = properties
+
+		if len(section.Properties) == 0 && len(section.Comments) == 0 {
+			continue
+		}
+		result = append(result, section)
+	}
+	return result
+}
+
+// Canonicalize brings a parsed file into its canonical form
+func Canonicalize(file File) File {
+	file.Preamble = normalizeProperties(file.Preamble)
+	for i := range file.Sections {
+		file.Sections[i].Properties = normalizeProperties(file.Sections[i].Properties)
+	}
+
+	file.Sections = mergeAdjacentSections(file.Sections)
+	orderSections(file.Sections)
+	file.Sections = mergeAdjacentSections(file.Sections)
+	file.Sections = removeRedundantProperties(file.Sections)
+
+	return file
+}
+
+// String returns the file in the .editorconfig format
+func (f File) String() string {
+	var builder strings.Builder
+
+	writeProperties := func(properties []Property) {
+		for _, property := range properties {
+			for _, comment := range property.Comments {
+				builder.WriteString(comment + "\n")
+			}
+			builder.WriteString(property.Key + " = " + property.Value + "\n")
+		}
+	}
+
+	writeProperties(f.Preamble)
+
+	for _, section := range f.Sections {
+		if builder.Len() > 0 {
+			builder.WriteString("\n")
+		}
+		for _, comment := range section.Comments {
+			builder.WriteString(comment + "\n")
+		}
+		builder.WriteString("[" + section.Glob + "]\n")
+		writeProperties(section.Properties)
+	}
+
+	if len(f.Comments) > 0 && builder.Len() > 0 {
+		builder.WriteString("\n")
+	}
+	for _, comment := range f.Comments {
+		builder.WriteString(comment + "\n")
+	}
+
+	return builder.String()
+}
+
+// Format returns the canonical form of the content of an .editorconfig file,
+// keeping a byte order mark and CRLF line endings if the content uses them
+func Format(content []byte) ([]byte, error) {
+	file, err := Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	formatted := Canonicalize(file).String()
+	if bytes.Contains(content, []byte("\r\n")) {
+		formatted = strings.ReplaceAll(formatted, "\n", "\r\n")
+	}
+	if bytes.HasPrefix(content, []byte("\uFEFF")) {
+		formatted = "\uFEFF" + formatted
+	}
+
+	return []byte(formatted), nil
+}
@@ -0,0 +1,125 @@
+package editorconfigfmt
+
+import (
+	"os"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	formatTests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			"already canonical",
+			"root = true\n\n[*]\nindent_style = space\n",
+			"root = true\n\n[*]\nindent_style = space\n",
+		},
+		{
+			"key casing, value casing and spacing",
+			"ROOT=True\n[*]\n  Indent_Style   =Space\nEnd_Of_Line= LF\n",
+			"root = true\n\n[*]\nindent_style = space\nend_of_line = lf\n",
+		},
+		{
+			"unknown values keep their case",
+			"[*]\nx_custom = KeepMe\nspelling_language = en-US\n",
+			"[*]\nspelling_language = en-US\nx_custom = KeepMe\n",
+		},
+		{
+			"canonical property order and last duplicate wins",
+			"[*]\ninsert_final_newline = true\nindent_size = 2\nindent_style = space\nindent_size = 4\n",
+			"[*]\nindent_style = space\nindent_size = 4\ninsert_final_newline = true\n",
+		},
+		{
+			"comments stay attached",
+			"# header\nroot = true\n\n# all files\n[*]\n; the style\nindent_style = tab\n# trailing\n",
+			"# header\nroot = true\n\n# all files\n[*]\n; the style\nindent_style = tab\n\n# trailing\n",
+		},
+		{
+			"commuting sections are ordered",
+			"[Makefile]\nindent_style = tab\n\n[*.md]\nmax_line_length = off\n\n[*]\ncharset = utf-8\n",
+			"[*]\ncharset = utf-8\n\n[*.md]\nmax_line_length = off\n\n[Makefile]\nindent_style = tab\n",
+		},
+		{
+			"overriding sections keep their order",
+			"[*.go]\nindent_style = tab\n\n[*]\nindent_style = space\n",
+			"[*.go]\nindent_style = tab\n\n[*]\nindent_style = space\n",
+		},
+		{
+			"redundant properties are removed",
+			"[*]\nend_of_line = lf\nindent_style = space\n\n[*.go]\nend_of_line = LF\nindent_style = tab\n",
+			"[*]\nindent_style = space\nend_of_line = lf\n\n[*.go]\nindent_style = tab\n",
+		},
+		{
+			"sections left empty are removed",
+			"[*]\nend_of_line = lf\n\n[*.go]\nend_of_line = lf\n",
+			"[*]\nend_of_line = lf\n",
+		},
+		{
+			"properties overridden in between are not redundant",
+			"[*]\nindent_size = 4\n\n[*.go]\nindent_size = 2\n\n[*]\nindent_size = 4\n",
+			"[*]\nindent_size = 4\n\n[*.go]\nindent_size = 2\n\n[*]\nindent_size = 4\n",
+		},
+		{
+			"sections not covering all files do not make properties redundant",
+			"[*.go]\nindent_style = tab\n\n[{*.go,Makefile}]\nindent_style = tab\n",
+			"[*.go]\nindent_style = tab\n\n[{*.go,Makefile}]\nindent_style = tab\n",
+		},
+		{
+			"adjacent sections with the same glob are merged",
+			"[*.go]\nindent_style = tab\n\n[*.go]\ninsert_final_newline = true\n",
+			"[*.go]\nindent_style = tab\ninsert_final_newline = true\n",
+		},
+		{
+			"crlf line endings are kept",
+			"root=true\r\n[*]\r\nindent_style=tab\r\n",
+			"root = true\r\n\r\n[*]\r\nindent_style = tab\r\n",
+		},
+	}
+
+	for _, tt := range formatTests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := Format([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Format(%q): unexpected error %v", tt.input, err)
+			}
+			if string(actual) != tt.expected {
+				t.Errorf("Format(%q):\nexpected:\n%q\ngot:\n%q", tt.input, tt.expected, string(actual))
+			}
+
+			again, err := Format(actual)
+			if err != nil || string(again) != string(actual) {
+				t.Errorf("Format is not idempotent for %q, got %q", actual, again)
+			}
+		})
+	}
+}
+
+func TestFormatInvalid(t *testing.T) {
+	invalidTests := []string{
+		"[*\nindent_style = tab\n",
+		"[*]\nindent_style\n",
+	}
+
+	for _, input := range invalidTests {
+		if _, err := Format([]byte(input)); err == nil {
+			t.Errorf("Format(%q): expected an error, got nil", input)
+		}
+	}
+}
+
+func TestFormatOurEditorconfig(t *testing.T) {
+	content, err := os.ReadFile("../../.editorconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	formatted, err := Format(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(formatted) != string(content) {
+		t.Errorf("expected our own .editorconfig to be formatted, got:\n%s", formatted)
+	}
+}
@@ -2,8 +2,10 @@
 package encoding
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"strings"
 	"unicode/utf8"
 
@@ -17,6 +19,7 @@ import (
 	"golang.org/x/text/encoding/traditionalchinese"
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/encoding/unicode/utf32"
+	"golang.org/x/text/transform"
 )
 
 const (
@@ -44,6 +47,10 @@ const (
 	// UnknownEncoding is returned if the encoding could not be determined.
 	UnknownEncoding = "unknown"
 
+	// SampleSize is the number of bytes at the start of a stream its encoding
+	// is detected on by NewReader.
+	SampleSize = 64 * 1024
+
 	// See https://spec.editorconfig.org/#supported-pairs
 	// CharsetUnset defines the value allowing for file encoding.
 	CharsetUnset = "unset"
@@ -306,6 +313,113 @@ func Decode(contentBytes []byte) (string, string, error) {
 	return decodedContentString, encoding, nil
 }
 
+// Reader decodes a stream to UTF-8 while it is read, so its content does not
+// have to be held in memory as a whole.
+type Reader struct {
+	reader  io.Reader
+	charset string
+
+	// checkUTF8 is set when the sample was detected as ascii, but the stream is
+	// longer than the sample, so its charset is only known once it was read.
+	checkUTF8 bool
+	nonASCII  bool
+	invalid   bool
+	// pending holds the bytes of a rune which continues in the next read
+	pending []byte
+}
+
+// NewReader detects the character encoding of the first SampleSize bytes of
+// a stream and returns a reader of its content decoded to UTF-8.
+// A stream which fits into the sample is detected like with Decode.
+// Like Decode, if the encoding cannot be decoded, the error is returned
+// together with a reader of the undecoded content.
+func NewReader(reader io.Reader) (*Reader, error) {
+	buffered := bufio.NewReaderSize(reader, SampleSize)
+	// a read error is returned again by the reads of the stream
+	sample, _ := buffered.Peek(SampleSize)
+	complete := len(sample) < SampleSize
+
+	detectionSample := sample
+	if !complete {
+		detectionSample = trimIncompleteRune(sample)
+	}
+	encoding, _, _ := Detect(detectionSample)
+
+	// See Decode for why binary data is checked before decoding.
+	if IsStrictBinary(sample) && !isMultiByteEncoding(encoding) {
+		return &Reader{reader: buffered, charset: BinaryData}, nil
+	}
+
+	// ascii needs no decoding, but the rest of the stream might not be ascii
+	if encoding == consts.Ascii {
+		return &Reader{reader: buffered, charset: encoding, checkUTF8: !complete}, nil
+	}
+
+	enc, ok := getDecoder(encoding)
+	if !ok {
+		if IsBinary(sample) {
+			return &Reader{reader: buffered, charset: BinaryData}, nil
+		}
+		return &Reader{reader: buffered, charset: encoding}, &UnrecogizedEncodingError{encoding}
+	}
+
+	return &Reader{reader: transform.NewReader(buffered, enc.NewDecoder()), charset: encoding}, nil
+}
+
+// Read reads the decoded content
+func (r *Reader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if r.checkUTF8 && n > 0 {
+		r.track(p[:n])
+	}
+	return n, err
+}
+
+// track records whether the content read so far is ascii or valid UTF-8
+func (r *Reader) track(content []byte) {
+	if r.invalid {
+		return
+	}
+	if len(r.pending) == 0 && !containsAnyByte(content, hiChars) {
+		return
+	}
+	r.nonASCII = true
+
+	content = append(r.pending, content...)
+	complete := trimIncompleteRune(content)
+	r.invalid = !utf8.Valid(complete)
+	r.pending = append([]byte(nil), content[len(complete):]...)
+}
+
+// Charset returns the name of the detected encoding, like the one returned by
+// Decode. A stream detected as ascii, but continuing with valid UTF-8 beyond
+// the sample, is reported as UTF-8 once it was read completely, and as
+// UnknownEncoding if it continues with anything else.
+func (r *Reader) Charset() string {
+	if !r.checkUTF8 || !r.nonASCII {
+		return r.charset
+	}
+	if r.invalid || len(r.pending) != 0 {
+		return UnknownEncoding
+	}
+	return consts.UTF8
+}
+
+// trimIncompleteRune removes an incomplete UTF-8 encoded rune from the end of
+// the content, as it is cut off when reading in parts.
+func trimIncompleteRune(content []byte) []byte {
+	for i := 1; i <= utf8.UTFMax-1 && i <= len(content); i++ {
+		if !utf8.RuneStart(content[len(content)-i]) {
+			continue
+		}
+		if !utf8.FullRune(content[len(content)-i:]) {
+			return content[:len(content)-i]
+		}
+		break
+	}
+	return content
+}
+
 // DecodeBytes is deprecated and may be removed in the future.
 // Use Decode instead.
 func DecodeBytes(contentBytes []byte) (string, string, error) {
@@ -1,9 +1,11 @@
 package encoding
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
@@ -891,3 +893,58 @@ func encodingToCharset(encoding string) string {
 func equal(name1, name2 string) bool {
 	return normalizeName(name1) == normalizeName(name2)
 }
+
+func TestNewReader(t *testing.T) {
+	// a stream which fits into the sample is decoded like with Decode
+	for _, tt := range tests {
+		fileContent, err := readFile(tt.Filename)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.Filename, err.Error())
+		}
+		if len(fileContent) >= SampleSize {
+			continue
+		}
+
+		wantContent, wantCharset, wantErr := Decode(fileContent)
+		reader, err := NewReader(bytes.NewReader(fileContent))
+		if (err != nil) != (wantErr != nil) {
+			t.Errorf("NewReader(%v): got error %v, want %v", tt.Filename, err, wantErr)
+			continue
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			t.Errorf("NewReader(%v): %s", tt.Filename, err.Error())
+			continue
+		}
+		if string(content) != wantContent || reader.Charset() != wantCharset {
+			t.Errorf("NewReader(%v): got charset %q, want %q, or different content", tt.Filename, reader.Charset(), wantCharset)
+		}
+	}
+
+	asciiSample := strings.Repeat("ascii\n", SampleSize/6+1)
+	streams := []struct {
+		name    string
+		content string
+		charset string
+	}{
+		{"ascii", asciiSample + "more ascii\n", consts.Ascii},
+		{"utf-8 after the sample", asciiSample + "café\n", consts.UTF8},
+		{"latin1 after the sample", asciiSample + "caf\xe9\n", UnknownEncoding},
+	}
+	for _, stream := range streams {
+		reader, err := NewReader(strings.NewReader(stream.content))
+		if err != nil {
+			t.Fatalf("NewReader(%s): %s", stream.name, err.Error())
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("NewReader(%s): %s", stream.name, err.Error())
+		}
+		if string(content) != stream.content {
+			t.Errorf("NewReader(%s): the content changed", stream.name)
+		}
+		if charset := reader.Charset(); charset != stream.charset {
+			t.Errorf("NewReader(%s): got charset %q, want %q", stream.name, charset, stream.charset)
+		}
+	}
+}
@@ -2,7 +2,10 @@
 package error
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 
 	// x-release-please-start-major
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
@@ -16,6 +19,18 @@ type ValidationError struct {
 	LineNumber                    int
 	Message                       error
 	AdditionalIdenticalErrorCount int
+	// Rule names the check which found the error
+	Rule string
+	// LineHash identifies the content of the line the error was found on,
+	// it is empty for errors concerning the whole file
+	LineHash string
+	// Severity is the severity of the rule, an empty one is config.SeverityError
+	Severity string
+}
+
+// IsWarning returns whether the error is reported without failing the run
+func (error1 ValidationError) IsWarning() bool {
+	return error1.Severity == config.SeverityWarning
 }
 
 // ValidationErrors represents which errors occurred in a file
@@ -38,6 +53,20 @@ func (error1 *ValidationError) Equal(error2 ValidationError) bool {
 
 }
 
+// messageWithRule returns the message of the error followed by its rule in brackets, if it has one
+func (error1 ValidationError) messageWithRule() string {
+	if error1.Rule == "" {
+		return error1.Message.Error()
+	}
+	return fmt.Sprintf("%s [%s]", error1.Message, error1.Rule)
+}
+
+// HashLine returns the LineHash of the content of a line
+func HashLine(line string) string {
+	sum := sha256.Sum256([]byte(line))
+	return hex.EncodeToString(sum[:])
+}
+
 // GetErrorCount returns the amount of errors
 func GetErrorCount(errors []ValidationErrors) int {
 	var errorCount = 0
@@ -49,6 +78,26 @@ func GetErrorCount(errors []ValidationErrors) int {
 	return errorCount
 }
 
+// GetFailingErrorCount returns the amount of errors which are not warnings, and so fail the run
+func GetFailingErrorCount(errors []ValidationErrors) int {
+	var errorCount = 0
+
+	for _, v := range errors {
+		for _, singleError := range v.Errors {
+			if !singleError.IsWarning() {
+				errorCount++
+			}
+		}
+	}
+
+	return errorCount
+}
+
+// GetWarningCount returns the amount of errors which are warnings, and so do not fail the run
+func GetWarningCount(errors []ValidationErrors) int {
+	return GetErrorCount(errors) - GetFailingErrorCount(errors)
+}
+
 func ConsolidateErrors(errors []ValidationError, config config.Config) []ValidationError {
 	var lineLessErrors []ValidationError
 	var errorsWithLines []ValidationError
@@ -88,108 +137,178 @@ func ConsolidateErrors(errors []ValidationError, config config.Config) []Validat
 }
 
 func PrintErrorCount(errorCount int, config config.Config) {
-	if errorCount == 0 {
+	PrintErrorAndWarningCount(errorCount, 0, config)
+}
+
+// PrintErrorAndWarningCount prints the number of errors and of warnings found,
+// as an error only if there are errors which fail the run
+func PrintErrorAndWarningCount(errorCount int, warningCount int, config config.Config) {
+	switch {
+	case errorCount == 0 && warningCount == 0:
 		config.Logger.Verbose("\n%d errors found", errorCount)
-		return
+	case warningCount == 0:
+		config.Logger.Error("\n%d errors found", errorCount)
+	case errorCount == 0:
+		config.Logger.Warning("\n%d warnings found", warningCount)
+	default:
+		config.Logger.Error("\n%d errors and %d warnings found", errorCount, warningCount)
 	}
-	config.Logger.Error("\n%d errors found", errorCount)
+}
+
+// counts are the numbers of errors and of warnings printed
+type counts struct {
+	errors   int
+	warnings int
+}
+
+// add adds the counts of the errors of a file
+func (c *counts) add(other counts) {
+	c.errors += other.errors
+	c.warnings += other.warnings
+}
+
+// countErrors returns the counts of the errors
+func countErrors(errors []ValidationError) counts {
+	var c counts
+	for _, singleError := range errors {
+		if singleError.IsWarning() {
+			c.warnings++
+		} else {
+			c.errors++
+		}
+	}
+	return c
 }
 
 func PrintErrorsAsHumanReadable(errors []ValidationErrors, config config.Config) {
-	errorCount := 0
+	var printed counts
 	for _, fileErrors := range errors {
-		if len(fileErrors.Errors) == 0 {
-			continue
-		}
+		printed.add(printFileErrorsAsHumanReadable(fileErrors, config))
+	}
+	PrintErrorAndWarningCount(printed.errors, printed.warnings, config)
+}
 
-		relativeFilePath, err := files.GetRelativePath(fileErrors.FilePath)
-		if err != nil {
-			config.Logger.Error("%v", err.Error())
-			continue
-		}
+// printFileErrorsAsHumanReadable prints the errors of a file in the human readable format
+// and returns the counts of the errors printed
+func printFileErrorsAsHumanReadable(fileErrors ValidationErrors, config config.Config) counts {
+	if len(fileErrors.Errors) == 0 {
+		return counts{}
+	}
 
-		fileErrors.Errors = ConsolidateErrors(fileErrors.Errors, config)
+	relativeFilePath, err := files.GetRelativePath(fileErrors.FilePath)
+	if err != nil {
+		config.Logger.Error("%v", err.Error())
+		return counts{}
+	}
 
-		config.Logger.Warning("%s:", relativeFilePath)
-		for _, singleError := range fileErrors.Errors {
-			errorCount++
+	fileErrors.Errors = ConsolidateErrors(fileErrors.Errors, config)
 
-			if singleError.LineNumber == -1 {
-				config.Logger.Error("\t%s", singleError.Message)
-				continue
-			}
+	config.Logger.Warning("%s:", relativeFilePath)
+	for _, singleError := range fileErrors.Errors {
+		// warnings are printed in the color of the file names rather than the one of errors
+		printError := config.Logger.Error
+		if singleError.IsWarning() {
+			printError = config.Logger.Warning
+		}
 
-			if singleError.AdditionalIdenticalErrorCount == 0 {
-				config.Logger.Error("\t%d: %s", singleError.LineNumber, singleError.Message)
-				continue
-			}
+		if singleError.LineNumber == -1 {
+			printError("\t%s", singleError.messageWithRule())
+			continue
+		}
 
-			config.Logger.Error("\t%d-%d: %s", singleError.LineNumber, singleError.LineNumber+singleError.AdditionalIdenticalErrorCount, singleError.Message)
+		if singleError.AdditionalIdenticalErrorCount == 0 {
+			printError("\t%d: %s", singleError.LineNumber, singleError.messageWithRule())
+			continue
 		}
+
+		printError("\t%d-%d: %s", singleError.LineNumber, singleError.LineNumber+singleError.AdditionalIdenticalErrorCount, singleError.messageWithRule())
 	}
-	PrintErrorCount(errorCount, config)
+	return countErrors(fileErrors.Errors)
 }
 
 func PrintErrorsAsGHA(errors []ValidationErrors, config config.Config) {
-	errorCount := 0
+	var printed counts
 	for _, fileErrors := range errors {
-		if len(fileErrors.Errors) == 0 {
-			continue
-		}
+		printed.add(printFileErrorsAsGHA(fileErrors, config))
+	}
+	PrintErrorAndWarningCount(printed.errors, printed.warnings, config)
+}
 
-		relativeFilePath, err := files.GetRelativePath(fileErrors.FilePath)
-		if err != nil {
-			config.Logger.Error("%v", err.Error())
-			continue
-		}
+// printFileErrorsAsGHA prints the errors of a file as GitHub Actions commands
+// and returns the counts of the errors printed
+func printFileErrorsAsGHA(fileErrors ValidationErrors, config config.Config) counts {
+	if len(fileErrors.Errors) == 0 {
+		return counts{}
+	}
 
-		fileErrors.Errors = ConsolidateErrors(fileErrors.Errors, config)
+	relativeFilePath, err := files.GetRelativePath(fileErrors.FilePath)
+	if err != nil {
+		config.Logger.Error("%v", err.Error())
+		return counts{}
+	}
 
-		// github-actions: A format dedicated for usage in Github Actions
-		for _, singleError := range fileErrors.Errors {
-			errorCount++
+	fileErrors.Errors = ConsolidateErrors(fileErrors.Errors, config)
 
-			if singleError.LineNumber == -1 {
-				config.Logger.Error("::error file=%s::%s", relativeFilePath, singleError.Message)
-				continue
-			}
+	// github-actions: A format dedicated for usage in Github Actions
+	for _, singleError := range fileErrors.Errors {
+		command, printError := "error", config.Logger.Error
+		if singleError.IsWarning() {
+			command, printError = "warning", config.Logger.Warning
+		}
+		// the rule is the title of the annotation
+		var title string
+		if singleError.Rule != "" {
+			title = ",title=" + singleError.Rule
+		}
 
-			if singleError.AdditionalIdenticalErrorCount == 0 {
-				config.Logger.Error("::error file=%s,line=%d::%s", relativeFilePath, singleError.LineNumber, singleError.Message)
-				continue
-			}
+		if singleError.LineNumber == -1 {
+			printError("::%s file=%s%s::%s", command, relativeFilePath, title, singleError.Message)
+			continue
+		}
 
-			config.Logger.Error("::error file=%s,line=%d,endLine=%d::%s", relativeFilePath, singleError.LineNumber, singleError.LineNumber+singleError.AdditionalIdenticalErrorCount, singleError.Message)
+		if singleError.AdditionalIdenticalErrorCount == 0 {
+			printError("::%s file=%s,line=%d%s::%s", command, relativeFilePath, singleError.LineNumber, title, singleError.Message)
+			continue
 		}
+
+		printError("::%s file=%s,line=%d,endLine=%d%s::%s", command, relativeFilePath, singleError.LineNumber, singleError.LineNumber+singleError.AdditionalIdenticalErrorCount, title, singleError.Message)
 	}
-	PrintErrorCount(errorCount, config)
+	return countErrors(fileErrors.Errors)
 }
 
 // gcc: A format mimicking the error format from GCC.
 func PrintErrorsAsGCC(errors []ValidationErrors, config config.Config) {
-	errorCount := 0
+	var printed counts
 	for _, fileErrors := range errors {
-		if len(fileErrors.Errors) == 0 {
-			continue
-		}
+		printed.add(printFileErrorsAsGCC(fileErrors, config))
+	}
+	PrintErrorAndWarningCount(printed.errors, printed.warnings, config)
+}
 
-		relativeFilePath, err := files.GetRelativePath(fileErrors.FilePath)
-		if err != nil {
-			config.Logger.Error("%v", err.Error())
-			continue
-		}
+// printFileErrorsAsGCC prints the errors of a file in the format of GCC and returns the counts of the errors printed
+func printFileErrorsAsGCC(fileErrors ValidationErrors, config config.Config) counts {
+	if len(fileErrors.Errors) == 0 {
+		return counts{}
+	}
 
-		for _, singleError := range fileErrors.Errors {
-			errorCount++
+	relativeFilePath, err := files.GetRelativePath(fileErrors.FilePath)
+	if err != nil {
+		config.Logger.Error("%v", err.Error())
+		return counts{}
+	}
 
-			lineNo := 0
-			if singleError.LineNumber > 0 {
-				lineNo = singleError.LineNumber
-			}
-			config.Logger.Error("%s:%d:%d: %s: %s", relativeFilePath, lineNo, 0, "error", singleError.Message)
+	for _, singleError := range fileErrors.Errors {
+		lineNo := 0
+		if singleError.LineNumber > 0 {
+			lineNo = singleError.LineNumber
+		}
+		severity, printError := "error", config.Logger.Error
+		if singleError.IsWarning() {
+			severity, printError = "warning", config.Logger.Warning
 		}
+		printError("%s:%d:%d: %s: %s", relativeFilePath, lineNo, 0, severity, singleError.messageWithRule())
 	}
-	PrintErrorCount(errorCount, config)
+	return countErrors(fileErrors.Errors)
 }
 
 // codeclimate: A format that is compatible with the codeclimate format for GitLab CI.
@@ -122,6 +122,26 @@ func TestGetErrorCount(t *testing.T) {
 	}
 }
 
+func TestGetFailingErrorCount(t *testing.T) {
+	input := []ValidationErrors{
+		{
+			FilePath: "some/path",
+			Errors: []ValidationError{
+				{LineNumber: 1, Message: errors.New("WRONG")},
+				{LineNumber: 2, Message: errors.New("WRONG"), Severity: config.SeverityError},
+				{LineNumber: 3, Message: errors.New("WRONG"), Severity: config.SeverityWarning},
+			},
+		},
+	}
+
+	if count := GetFailingErrorCount(input); count != 2 {
+		t.Error("Expected the warning not to be counted, got", count)
+	}
+	if count := GetErrorCount(input); count != 3 {
+		t.Error("Expected the warning to be counted among all errors, got", count)
+	}
+}
+
 func TestValidationErrorEqual(t *testing.T) {
 	baseError := ValidationError{
 		LineNumber: -1,
@@ -334,11 +354,18 @@ func TestFormatErrors(t *testing.T) {
 				{LineNumber: 1, Message: errors.New("message kind one")},
 				{LineNumber: 2, Message: errors.New("message kind one")},
 				{LineNumber: 4, Message: errors.New("message kind one")},
-				{LineNumber: 5, Message: errors.New("message kind two")},
+				{LineNumber: 5, Message: errors.New("message kind two"), Rule: "some-rule"},
 				{LineNumber: 6, Message: errors.New("message kind one")},
 				{LineNumber: -1, Message: errors.New("file-level error")},
 			},
 		},
+		{
+			FilePath: "some/file/with/warnings",
+			Errors: []ValidationError{
+				{LineNumber: 3, Message: errors.New("a warning"), Severity: config.SeverityWarning, Rule: "some-rule"},
+				{LineNumber: -1, Message: errors.New("file-level warning"), Severity: config.SeverityWarning, Rule: "other-rule"},
+			},
+		},
 	}
 
 	for _, format := range outputformat.ValidOutputFormats {
@@ -353,16 +380,80 @@ func TestFormatErrors(t *testing.T) {
 	}
 }
 
+func TestStreamPrinter(t *testing.T) {
+	input := []ValidationErrors{
+		{FilePath: "first", Errors: []ValidationError{{LineNumber: 1, Message: errors.New("WRONG")}, {LineNumber: 2, Message: errors.New("WRONG")}}},
+		{FilePath: "second"},
+		{FilePath: "third", Errors: []ValidationError{{LineNumber: -1, Message: errors.New("a warning"), Severity: config.SeverityWarning}}},
+		{FilePath: "fourth", Errors: []ValidationError{{LineNumber: 3, Message: errors.New("WRONG")}}},
+	}
+
+	for _, format := range outputformat.ValidOutputFormats {
+		if !IsStreamable(format) {
+			continue
+		}
+		t.Run(string(format), func(t *testing.T) {
+			expected := bytes.Buffer{}
+			configuration := config.NewConfig(nil)
+			configuration.Format = format
+			configuration.Logger.SetWriter(&expected)
+			PrintErrors(input, *configuration)
+
+			// the files validated out of order are printed in order
+			buffer := bytes.Buffer{}
+			configuration.Logger.SetWriter(&buffer)
+			printer := NewStreamPrinter(*configuration, true)
+			for _, index := range []int{2, 0, 3, 1} {
+				printer.Print(index, input[index])
+			}
+			printer.Close()
+			if buffer.String() != expected.String() {
+				t.Errorf("expected the output of PrintErrors %q, got %q", expected.String(), buffer.String())
+			}
+			if printer.FailingErrorCount() != 3 {
+				t.Errorf("expected 3 failing errors, got %d", printer.FailingErrorCount())
+			}
+
+			// a file which is never passed does not hold back the following ones for ever
+			buffer.Reset()
+			printer = NewStreamPrinter(*configuration, true)
+			printer.Print(3, input[3])
+			if buffer.Len() != 0 {
+				t.Errorf("expected the fourth file to be held back, got %q", buffer.String())
+			}
+			printer.Close()
+			if !bytes.Contains(buffer.Bytes(), []byte("fourth")) {
+				t.Errorf("expected the fourth file to be printed on Close, got %q", buffer.String())
+			}
+		})
+	}
+
+	// unordered, the files are printed as they are passed
+	buffer := bytes.Buffer{}
+	configuration := config.NewConfig(nil)
+	configuration.Format = outputformat.GCC
+	configuration.Logger.SetWriter(&buffer)
+	configuration.Logger.NoColor = true
+	printer := NewStreamPrinter(*configuration, false)
+	printer.Print(3, input[3])
+	if !bytes.HasPrefix(buffer.Bytes(), []byte("fourth:3:0: error: WRONG")) {
+		t.Errorf("expected the fourth file to be printed right away, got %q", buffer.String())
+	}
+}
+
 func TestPrintErrorCount(t *testing.T) {
 	tests := []struct {
-		name       string
-		verbose    bool
-		errorcount int
+		name         string
+		verbose      bool
+		errorcount   int
+		warningcount int
 	}{
-		{"nonverbose-zero", false, 0},
-		{"verbose-zero", true, 0},
-		{"nonverbose-ten", false, 10},
-		{"verbose-ten", true, 10},
+		{"nonverbose-zero", false, 0, 0},
+		{"verbose-zero", true, 0, 0},
+		{"nonverbose-ten", false, 10, 0},
+		{"verbose-ten", true, 10, 0},
+		{"nonverbose-warnings", false, 0, 2},
+		{"nonverbose-ten-and-warnings", false, 10, 2},
 	}
 
 	for _, test := range tests {
@@ -371,7 +462,7 @@ func TestPrintErrorCount(t *testing.T) {
 			config := config.NewConfig(nil)
 			config.Logger.VerboseEnabled = test.verbose
 			config.Logger.SetWriter(&buffer)
-			PrintErrorCount(test.errorcount, *config)
+			PrintErrorAndWarningCount(test.errorcount, test.warningcount, *config)
 			snaps.MatchSnapshot(t, buffer.String())
 		})
 	}
@@ -27,18 +27,29 @@ type CodeclimateIssue struct {
 }
 
 const (
+	// checkName is the check_name of the errors without a rule
 	checkName = "editorconfig-checker"
 	severity  = "minor"
+	// warningSeverity is the severity of the errors of rules set to warning
+	warningSeverity = "info"
 )
 
 func newCodeclimateIssue(err ValidationError, path string) CodeclimateIssue {
 	toHash := fmt.Sprintf("%s:%d:%d:%s", path, err.LineNumber, err.AdditionalIdenticalErrorCount, err.Message.Error())
 	fingerprint := fmt.Sprintf("%x", md5.Sum([]byte(toHash)))
+	issueSeverity := severity
+	if err.IsWarning() {
+		issueSeverity = warningSeverity
+	}
+	issueCheckName := checkName
+	if err.Rule != "" {
+		issueCheckName = err.Rule
+	}
 	return CodeclimateIssue{
-		Check:       checkName,
+		Check:       issueCheckName,
 		Description: err.Message.Error(),
 		Fingerprint: fingerprint,
-		Severity:    severity,
+		Severity:    issueSeverity,
 		Location: CodeclimateLocation{
 			Path: path,
 			Lines: CodeclimateLines{
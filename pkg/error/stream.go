@@ -0,0 +1,99 @@
+package error
+
+import (
+	"sync"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/outputformat"
+	// x-release-please-end
+)
+
+// IsStreamable returns whether the errors of the format can be printed file by file,
+// which is the case for the line-oriented formats but not for codeclimate, which is a single JSON document
+func IsStreamable(format outputformat.OutputFormat) bool {
+	return format != outputformat.Codeclimate
+}
+
+// StreamPrinter prints the errors of every file as soon as the file is validated,
+// rather than after all files were validated like PrintErrors.
+// Its methods are safe for concurrent use.
+type StreamPrinter struct {
+	config  config.Config
+	ordered bool
+
+	lock sync.Mutex
+	// next is the index of the file to print next when ordered
+	next int
+	// pending holds the files validated before the ones preceding them when ordered
+	pending map[int]ValidationErrors
+	// printed counts the errors and warnings printed
+	printed           counts
+	failingErrorCount int
+}
+
+// NewStreamPrinter returns a StreamPrinter for the format of the config, which must be streamable.
+// If ordered is set, the files are printed in the order of their indexes, like PrintErrors does,
+// and only the files validated before a preceding one are held back until it is printed.
+// Otherwise the files are printed in the order they are validated in.
+func NewStreamPrinter(config config.Config, ordered bool) *StreamPrinter {
+	return &StreamPrinter{config: config, ordered: ordered, pending: make(map[int]ValidationErrors)}
+}
+
+// Print prints the errors of the file with the index, every index from 0 must be passed exactly once when ordered
+func (p *StreamPrinter) Print(index int, fileErrors ValidationErrors) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.ordered {
+		p.print(fileErrors)
+		return
+	}
+
+	p.pending[index] = fileErrors
+	for {
+		nextErrors, ok := p.pending[p.next]
+		if !ok {
+			return
+		}
+		delete(p.pending, p.next)
+		p.next++
+		p.print(nextErrors)
+	}
+}
+
+// Close prints the files still held back, like the ones following a file which was never validated,
+// and the number of errors and of warnings found
+func (p *StreamPrinter) Close() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for len(p.pending) > 0 {
+		if nextErrors, ok := p.pending[p.next]; ok {
+			delete(p.pending, p.next)
+			p.print(nextErrors)
+		}
+		p.next++
+	}
+	PrintErrorAndWarningCount(p.printed.errors, p.printed.warnings, p.config)
+}
+
+// FailingErrorCount returns the number of errors passed which are not warnings, and so fail the run
+func (p *StreamPrinter) FailingErrorCount() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.failingErrorCount
+}
+
+// print prints the errors of a file in the format of the config
+func (p *StreamPrinter) print(fileErrors ValidationErrors) {
+	p.failingErrorCount += GetFailingErrorCount([]ValidationErrors{fileErrors})
+	switch p.config.Format {
+	case outputformat.GCC:
+		p.printed.add(printFileErrorsAsGCC(fileErrors, p.config))
+	case outputformat.GithubActions:
+		p.printed.add(printFileErrorsAsGHA(fileErrors, p.config))
+	default:
+		p.printed.add(printFileErrorsAsHumanReadable(fileErrors, p.config))
+	}
+}
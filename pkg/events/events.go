@@ -0,0 +1,55 @@
+// Package events notifies subscribers of the progress of a run, like for a progress bar
+package events
+
+// Kind is what happened to a file
+type Kind int
+
+const (
+	// FileDiscovered is sent when a file was found which is going to be checked
+	FileDiscovered Kind = iota
+	// FileSkipped is sent when a file was found which is not checked, the Reason says why
+	FileSkipped
+	// FileStarted is sent when the validation of a file starts
+	FileStarted
+	// FileFinished is sent when the validation of a file is done, with the number of errors found
+	FileFinished
+)
+
+// String returns the name of the kind
+func (k Kind) String() string {
+	switch k {
+	case FileDiscovered:
+		return "discovered"
+	case FileSkipped:
+		return "skipped"
+	case FileStarted:
+		return "started"
+	case FileFinished:
+		return "finished"
+	}
+	return "unknown"
+}
+
+// Event is something which happened to a file
+type Event struct {
+	Kind     Kind
+	FilePath string
+	// ErrorCount is the number of errors found in the file, for FileFinished
+	ErrorCount int
+	// Reason is why the file is not checked, for FileSkipped
+	Reason string
+}
+
+// Subscriber receives the events of a run.
+// Files are validated concurrently, so Notify must be safe for concurrent use.
+type Subscriber interface {
+	Notify(event Event)
+}
+
+// SubscriberFunc is a function which receives the events of a run
+type SubscriberFunc func(event Event)
+
+// Notify calls the function with the event
+func (f SubscriberFunc) Notify(event Event) {
+	f(event)
+}
@@ -0,0 +1,49 @@
+package events
+
+import (
+	"testing"
+)
+
+func TestKindString(t *testing.T) {
+	tests := []struct {
+		kind     Kind
+		expected string
+	}{
+		{FileDiscovered, "discovered"},
+		{FileSkipped, "skipped"},
+		{FileStarted, "started"},
+		{FileFinished, "finished"},
+		{Kind(-1), "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.kind.String(); got != tt.expected {
+			t.Errorf("%d.String() = %q, want %q", tt.kind, got, tt.expected)
+		}
+	}
+}
+
+func TestSubscriberFunc(t *testing.T) {
+	var received []Event
+	var subscriber Subscriber = SubscriberFunc(func(event Event) {
+		received = append(received, event)
+	})
+
+	sent := []Event{
+		{Kind: FileDiscovered, FilePath: "a.txt"},
+		{Kind: FileSkipped, FilePath: "b.txt", Reason: "it is excluded"},
+		{Kind: FileFinished, FilePath: "a.txt", ErrorCount: 2},
+	}
+	for _, event := range sent {
+		subscriber.Notify(event)
+	}
+
+	if len(received) != len(sent) {
+		t.Fatalf("expected %d events, got %d", len(sent), len(received))
+	}
+	for i := range sent {
+		if received[i] != sent[i] {
+			t.Errorf("expected event %d to be %+v, got %+v", i, sent[i], received[i])
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package files
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/run"
+	// x-release-please-end
+)
+
+// File is a file found by Discover
+type File struct {
+	// Index is the position of the file among the files found, they are numbered in the order GetFiles returns them
+	Index int
+	Path  string
+	// Skipped is set if the file should not be checked, because it is too large or has no allowed ContentType
+	Skipped bool
+	// Size is the size of the whole file in bytes, even if only its first lines are checked
+	Size int64
+	// Truncated is set if only the first LargeFileLines lines of the file are checked, as it is larger than the MaxFileSize
+	Truncated bool
+	// ContentType is the mime type detected while discovering the file
+	ContentType string
+	// Content is the content to check of a file inside of an archive or from the source of the run,
+	// which is read while discovering the file, so it need not be read again to validate it.
+	// It is nil for the files on the file system, which are read when they are validated.
+	Content []byte
+}
+
+// Discover finds the files which should be checked like GetFiles, but sends every file to found as soon as
+// its ContentType is detected, which is done by as many workers as the config has.
+// The files are sent in no particular order, the ones which should not be checked with Skipped set,
+// except for the excluded ones, which get no Index at all.
+// found is closed once all files are sent or the context is done, whose error is returned then.
+func Discover(ctx context.Context, config config.Config, state *run.State, found chan<- File) error {
+	defer close(found)
+
+	// create the cached matcher once, so it is shared by the copies of the config
+	config.CachedExcludeGlobs()
+	// an invalid exclude is reported for every file instead
+	_, _ = config.CachedExcludesAsRegexp()
+
+	candidates := make(chan File)
+	var wg sync.WaitGroup
+	for range config.Workers() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range candidates {
+				if ctx.Err() != nil {
+					continue
+				}
+				var ok bool
+				if file, ok = inspectFile(file, config, state); !ok {
+					// the content of a skipped file is not needed anymore
+					file.Skipped, file.Content = true, nil
+				}
+				select {
+				case found <- file:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+
+	// the excludes are not safe for concurrent use, so they are checked while listing the files
+	index := 0
+	err := listFiles(config, state, func(filePath string) error {
+		config.Logger.Debug("AddToFiles: investigating file %s", filePath)
+		if isExcludedFile(filePath, config, state) {
+			return nil
+		}
+		select {
+		case candidates <- File{Index: index, Path: filePath}:
+			index++
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	close(candidates)
+	wg.Wait()
+
+	if err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// DiscoverAll returns the files which should be checked like GetFiles, with what was found out about them while discovering them.
+// Their Index is their position in the returned slice. The Content of the files which are held in memory is kept,
+// so it is validated without reading it again, while the files on the file system are read when they are validated.
+// It stops when the context is done and returns its error.
+func DiscoverAll(ctx context.Context, config config.Config, state *run.State) ([]File, error) {
+	found := make(chan File)
+	done := make(chan struct{})
+	var discovered []File
+	go func() {
+		defer close(done)
+		for file := range found {
+			if !file.Skipped {
+				discovered = append(discovered, file)
+			}
+		}
+	}()
+
+	err := Discover(ctx, config, state, found)
+	<-done
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(discovered, func(i, j int) bool { return discovered[i].Index < discovered[j].Index })
+	for i := range discovered {
+		discovered[i].Index = i
+	}
+	return discovered, nil
+}
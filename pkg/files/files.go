@@ -3,11 +3,15 @@ package files
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -16,7 +20,12 @@ import (
 	"github.com/editorconfig/editorconfig-core-go/v2"
 
 	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/archive"
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/events"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/git"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/gitignore"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/run"
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/utils"
 	// x-release-please-end
 )
@@ -32,17 +41,31 @@ type FileInformation struct {
 	Editorconfig *editorconfig.Definition
 }
 
-// IsExcluded returns whether the file is excluded via arguments or config file
+// IsExcluded returns whether the file is excluded via arguments, config file or .ecignore files
 func IsExcluded(filePath string, config config.Config) (bool, error) {
-	if len(config.Exclude) == 0 && config.IgnoreDefaults {
-		return false, nil
-	}
+	return isExcluded(filePath, false, config, nil)
+}
 
+// isExcluded returns whether the file or directory is excluded
+// Glob excludes and .ecignore files take precedence over the regular expressions,
+// so a negated glob can re-include a path which a regular expression excludes.
+func isExcluded(filePath string, isDir bool, config config.Config, state *run.State) (bool, error) {
 	relativeFilePath, err := GetRelativePath(filePath)
 	if err != nil {
 		return true, err
 	}
 
+	if excluded, matched := config.CachedExcludeGlobs().Lookup(relativeFilePath, isDir); matched {
+		return excluded, nil
+	}
+	if excluded, matched := state.IgnoreFiles().Lookup(relativeFilePath, isDir); matched {
+		return excluded, nil
+	}
+
+	if len(config.Exclude) == 0 && config.IgnoreDefaults {
+		return false, nil
+	}
+
 	re, err := config.CachedExcludesAsRegexp()
 	if err != nil {
 		return true, err
@@ -53,29 +76,93 @@ func IsExcluded(filePath string, config config.Config) (bool, error) {
 // AddToFiles adds a file to a slice if it isn't already in there
 // and meets the requirements and returns the new slice
 func AddToFiles(filePaths []string, filePath string, config config.Config) []string {
+	return addToFiles(filePaths, filePath, config, nil)
+}
+
+// addToFiles adds a file to a slice like AddToFiles, with the state of the run
+func addToFiles(filePaths []string, filePath string, config config.Config, state *run.State) []string {
 	config.Logger.Debug("AddToFiles: investigating file %s", filePath)
 
-	isExcluded, err := IsExcluded(filePath, config)
+	if isExcludedFile(filePath, config, state) {
+		return filePaths
+	}
+	if _, ok := inspectFile(File{Path: filePath}, config, state); !ok {
+		return filePaths
+	}
+	return append(filePaths, filePath)
+}
+
+// isExcludedFile returns whether a file is excluded from being checked
+func isExcludedFile(filePath string, config config.Config, state *run.State) bool {
+	isExcluded, err := isExcluded(filePath, false, config, state)
 	if err == nil && isExcluded {
 		config.Logger.Verbose("Not adding %s to be checked, it is excluded", filePath)
-		return filePaths
+		state.Notify(events.Event{Kind: events.FileSkipped, FilePath: filePath, Reason: "it is excluded"})
+		return true
+	}
+	return false
+}
+
+// inspectFile fills in the Size and ContentType of a file which is not excluded, and returns whether it should be checked,
+// which is the case if it is not too large and has an allowed ContentType.
+// A file inside of an archive or from the source of the run is read into memory anyway,
+// so its Content is kept for the validation rather than being read again.
+// Unlike the excludes, it is safe to call concurrently.
+func inspectFile(file File, config config.Config, state *run.State) (File, bool) {
+	filePath := file.Path
+	var err error
+	if state.IsInMemory(filePath) {
+		if file.Content, file.Size, err = state.ReadFileWithSize(filePath); err == nil && file.Content == nil {
+			// an empty file is held in memory just as well
+			file.Content = []byte{}
+		}
+	} else {
+		var fileStat os.FileInfo
+		if fileStat, err = os.Stat(filePath); err == nil {
+			file.Size = fileStat.Size()
+		}
 	}
 
-	contentType, err := GetContentType(filePath)
+	if err == nil && config.MaxFileSize > 0 && file.Size > config.MaxFileSize {
+		if config.LargeFileLines <= 0 {
+			config.Logger.Verbose("Not adding %s to be checked, its size of %d bytes exceeds the MaxFileSize of %d bytes", filePath, file.Size, config.MaxFileSize)
+			state.Notify(events.Event{Kind: events.FileSkipped, FilePath: filePath, Reason: fmt.Sprintf("its size of %d bytes exceeds the MaxFileSize of %d bytes", file.Size, config.MaxFileSize)})
+			return file, false
+		}
+		config.Logger.Verbose("Only checking the first %d lines of %s, its size of %d bytes exceeds the MaxFileSize of %d bytes", config.LargeFileLines, filePath, file.Size, config.MaxFileSize)
+		file.Truncated = true
+		if file.Content != nil {
+			// the ContentType is detected before the content is truncated, like the one of a file on the file system
+			if file.ContentType, err = contentType(file); err == nil {
+				file.Content, err = truncateLines(bytes.NewReader(file.Content), config)
+			}
+			if err == nil && file.Content == nil {
+				// no line fits into the MaxFileSize, which leaves the content held in memory empty
+				file.Content = []byte{}
+			}
+		}
+	}
+
+	if err == nil && file.ContentType == "" {
+		file.ContentType, err = contentType(file)
+	}
 	if err != nil {
 		config.Logger.Error("Could not get the ContentType of file: %s", filePath)
 		config.Logger.Error("%v", err.Error())
-		return filePaths
+		state.Notify(events.Event{Kind: events.FileSkipped, FilePath: filePath, Reason: "its ContentType could not be detected"})
+		return file, false
 	}
-	config.Logger.Debug("AddToFiles: detected ContentType %s on file %s", contentType, filePath)
+	config.Logger.Debug("AddToFiles: detected ContentType %s on file %s", file.ContentType, filePath)
 
-	if !IsAllowedContentType(contentType, config) {
+	if !IsAllowedContentType(file.ContentType, config) {
 		config.Logger.Verbose("Not adding %s to be checked, it does not have an allowed ContentType", filePath)
-		return filePaths
+		state.Notify(events.Event{Kind: events.FileSkipped, FilePath: filePath, Reason: fmt.Sprintf("its ContentType %s is not allowed", file.ContentType)})
+		return file, false
 	}
 
 	config.Logger.Verbose("Adding %s to be checked", filePath)
-	return append(filePaths, filePath)
+	state.Notify(events.Event{Kind: events.FileDiscovered, FilePath: filePath})
+	return file, true
 }
 
 // hasGlobMeta reports whether the path contains any of the glob metacharacters
@@ -122,9 +209,29 @@ func resolvePassedFile(passedFile string) ([]string, error) {
 }
 
 // GetFilesFromDirectory returns all files from a directory and its subdirectories which should be checked
+// Files ignored by git are skipped like git ls-files does, the .gitignore files are read without the git binary.
 func GetFilesFromDirectory(rootDir string, config config.Config) ([]string, error) {
+	state := run.New()
 	filePaths := make([]string, 0)
-	err := fs.WalkDir(os.DirFS(rootDir), ".", func(path string, de fs.DirEntry, err error) error {
+	err := walkDirectory(rootDir, config, state, func(filePath string) error {
+		filePaths = addToFiles(filePaths, filePath, config, state)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return filePaths, nil
+}
+
+// walkDirectory calls add for every file of a directory and its subdirectories which is not ignored by git,
+// skipping the excluded subdirectories
+func walkDirectory(rootDir string, config config.Config, state *run.State, add func(filePath string) error) error {
+	ignored, err := gitignore.ForDirectory(rootDir)
+	if err != nil {
+		return fmt.Errorf("reading the .gitignore files of %s: %w", rootDir, err)
+	}
+
+	err = fs.WalkDir(os.DirFS(rootDir), ".", func(path string, de fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -135,10 +242,23 @@ func GetFilesFromDirectory(rootDir string, config config.Config) ([]string, erro
 		}
 
 		fullPath := filepath.Join(rootDir, path)
+		if path != "." && (ignored.Match(path, fi.IsDir()) || de.Name() == ".git") {
+			config.Logger.Verbose("Not adding %s to be checked, it is ignored by git", fullPath)
+			if fi.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if fi.IsDir() {
+			if err := ignored.AddFile(filepath.Join(fullPath, ".gitignore"), path); err != nil {
+				return err
+			}
+		}
+
 		if fi.Mode().IsRegular() {
-			filePaths = AddToFiles(filePaths, fullPath, config)
+			return add(fullPath)
 		} else if fi.IsDir() {
-			if isExcluded, err := IsExcluded(fullPath, config); err == nil && isExcluded {
+			if excluded, err := isExcluded(fullPath, true, config, state); err == nil && excluded {
 				config.Logger.Verbose("Not adding %s and subentries to be checked, it is excluded", fullPath)
 				return fs.SkipDir
 			}
@@ -147,65 +267,207 @@ func GetFilesFromDirectory(rootDir string, config config.Config) ([]string, erro
 		return nil
 	})
 	if err != nil {
-		return nil, fmt.Errorf("walking directory %s: %w", rootDir, err)
+		return fmt.Errorf("walking directory %s: %w", rootDir, err)
 	}
 
-	return filePaths, nil
+	return nil
+}
+
+// ChangedSinceRef returns the ref the working tree is compared against to only check changed files,
+// or an empty string if all files should be checked
+// Files without new lines have nothing to report with --new-lines-only, so it implies --changed-since.
+func ChangedSinceRef(config config.Config) (string, error) {
+	setOptions := 0
+	for _, option := range []string{config.ChangedSince, config.MergeBase, config.NewLinesOnly} {
+		if option != "" {
+			setOptions++
+		}
+	}
+	if setOptions > 1 {
+		return "", errors.New("--changed-since, --merge-base and --new-lines-only cannot be combined")
+	}
+
+	if config.MergeBase != "" {
+		return git.MergeBase(config.MergeBase)
+	}
+	if config.NewLinesOnly != "" {
+		return config.NewLinesOnly, nil
+	}
+	return config.ChangedSince, nil
+}
+
+// onlyChangedFiles wraps add, so it is only called for the files which changed since the ref
+func onlyChangedFiles(add func(filePath string) error, ref string, config config.Config) (func(filePath string) error, error) {
+	changedFiles, err := git.ChangedFiles(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[string]bool, len(changedFiles))
+	for _, changedFile := range changedFiles {
+		changed[path.Clean(changedFile)] = true
+	}
+
+	return func(filePath string) error {
+		relativeFilePath, err := GetRelativePath(filePath)
+		if err == nil && changed[path.Clean(relativeFilePath)] {
+			return add(filePath)
+		}
+		config.Logger.Verbose("Not checking %s, it did not change since %s", filePath, ref)
+		return nil
+	}, nil
+}
+
+// listSourceFiles calls add for the files of the source of the run
+// Passed files restrict the files to the ones among them or within passed directories.
+func listSourceFiles(config config.Config, state *run.State, add func(filePath string) error) error {
+	sourceFiles, err := state.Source.ListFiles()
+	if err != nil {
+		return err
+	}
+
+	passedPaths := make([]string, 0, len(config.PassedFiles))
+	for _, passedFile := range config.PassedFiles {
+		relativePassedFile, err := GetRelativePath(passedFile)
+		if err != nil {
+			return err
+		}
+		passedPaths = append(passedPaths, path.Clean(relativePassedFile))
+	}
+
+	for _, filePath := range sourceFiles {
+		if isWithinPaths(path.Clean(filePath), passedPaths) {
+			if err := add(filePath); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isWithinPaths returns whether the file is one of the paths or within one of them,
+// all files are within an empty list of paths
+func isWithinPaths(filePath string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, p := range paths {
+		if p == "." || filePath == p || strings.HasPrefix(filePath, p+"/") {
+			return true
+		}
+	}
+	return false
 }
 
 // GetFiles returns all files which should be checked
 func GetFiles(config config.Config) ([]string, error) {
-	filePaths := make([]string, 0)
+	discovered, err := DiscoverAll(context.Background(), config, run.New())
+	if err != nil {
+		return make([]string, 0), err
+	}
+
+	filePaths := make([]string, 0, len(discovered))
+	for _, file := range discovered {
+		filePaths = append(filePaths, file.Path)
+	}
+	return filePaths, nil
+}
+
+// listFiles calls add for every file found which is not ignored by git,
+// before it is known whether the file should be checked
+func listFiles(config config.Config, state *run.State, add func(filePath string) error) error {
+	ref, err := ChangedSinceRef(config)
+	if err != nil {
+		return err
+	}
+
+	if state != nil && state.Source != nil {
+		if ref != "" {
+			return errors.New("only the files of the working tree can be compared to a git ref")
+		}
+		return listSourceFiles(config, state, add)
+	}
 
 	// Handle explicit passed files
 	if len(config.PassedFiles) != 0 {
+		if ref != "" {
+			add, err = onlyChangedFiles(add, ref, config)
+			if err != nil {
+				return err
+			}
+		}
+
 		for _, passedFile := range config.PassedFiles {
 			resolved, err := resolvePassedFile(passedFile)
 			if err != nil {
-				return filePaths, err
+				return err
 			}
 			for _, entry := range resolved {
-				if utils.IsDirectory(entry) {
-					files, err := GetFilesFromDirectory(entry, config)
+				if archive.IsArchive(entry) && utils.IsRegularFile(entry) {
+					// an archive is checked by the files inside of it, even though archives are excluded by default
+					archiveFiles, err := state.ListArchive(entry)
 					if err != nil {
-						return filePaths, err
+						return err
 					}
-					filePaths = append(filePaths, files...)
-				} else {
-					filePaths = AddToFiles(filePaths, entry, config)
+					for _, archiveFile := range archiveFiles {
+						if err := add(archiveFile); err != nil {
+							return err
+						}
+					}
+				} else if utils.IsDirectory(entry) {
+					if err := walkDirectory(entry, config, state, add); err != nil {
+						return err
+					}
+				} else if err := add(entry); err != nil {
+					return err
 				}
 			}
 		}
 
-		return filePaths, nil
+		return nil
 	}
 
-	byteArray, err := exec.Command("git", "ls-files", "--cached", "--others", "--exclude-standard").Output()
-	if err != nil {
-		// It is not a git repository.
-		cwd, err := os.Getwd()
+	var filesSlice []string
+	if ref != "" {
+		// only the changed files are wanted, so not being in a git repository is an error here
+		filesSlice, err = git.ChangedFiles(ref)
 		if err != nil {
-			return filePaths, err
+			return err
 		}
+	} else {
+		var byteArray []byte
+		if !config.NoGit {
+			byteArray, err = exec.Command("git", "ls-files", "--cached", "--others", "--exclude-standard").Output()
+		}
+		if config.NoGit || err != nil {
+			// It is not a git repository, git is not available, or it should not be used.
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
 
-		return GetFilesFromDirectory(cwd, config)
-	}
+			return walkDirectory(cwd, config, state, add)
+		}
 
-	filesSlice := strings.SplitSeq(string(byteArray[:]), "\n")
+		filesSlice = strings.Split(string(byteArray[:]), "\n")
+	}
 
-	for filePath := range filesSlice {
+	for _, filePath := range filesSlice {
 		if len(filePath) > 0 {
 			fi, err := os.Stat(filePath)
 
 			// The err would be a broken symlink for example,
 			// so we want to program to continue but the file should not be checked
 			if err == nil && fi.Mode().IsRegular() {
-				filePaths = AddToFiles(filePaths, filePath, config)
+				if err := add(filePath); err != nil {
+					return err
+				}
 			}
 		}
 	}
 
-	return filePaths, nil
+	return nil
 }
 
 // ReadLines returns the lines from a file as a slice
@@ -213,6 +475,8 @@ func ReadLines(content string) []string {
 	var lines []string
 	stringReader := strings.NewReader(content)
 	fileScanner := bufio.NewScanner(stringReader)
+	// a line can be as long as the whole content
+	fileScanner.Buffer(nil, len(content)+1)
 	for fileScanner.Scan() {
 		lines = append(lines, fileScanner.Text())
 	}
@@ -220,6 +484,19 @@ func ReadLines(content string) []string {
 	return lines
 }
 
+// ReadLine reads the next line including its end of line characters into the buffer and returns it.
+// Unlike a bufio.Scanner it has no limit on the length of a line.
+func ReadLine(reader *bufio.Reader, buffer []byte) ([]byte, error) {
+	buffer = buffer[:0]
+	for {
+		part, err := reader.ReadSlice('\n')
+		buffer = append(buffer, part...)
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return buffer, err
+		}
+	}
+}
+
 // GetContentType returns the content type of a file
 func GetContentType(path string) (string, error) {
 	fileStat, err := os.Stat(path)
@@ -237,13 +514,111 @@ func GetContentType(path string) (string, error) {
 
 	fileContent, err := os.OpenFile(path, os.O_RDONLY, 0)
 	if err != nil {
-		panic(err)
+		return "", err
 	}
 	defer fileContent.Close()
 
 	return GetContentTypeBytes(fileContent)
 }
 
+// Open opens a file inside of an archive, from the source of the run, or the file system if none is set.
+// Of a file larger than the MaxFileSize only the first LargeFileLines lines are read, if set.
+func Open(filePath string, config config.Config, state *run.State) (io.ReadCloser, error) {
+	reader, _, err := OpenWithTruncation(filePath, config, state)
+	return reader, err
+}
+
+// OpenWithTruncation opens a file like Open, and returns whether only its first lines are read,
+// in which case the content does not end like the file does
+func OpenWithTruncation(filePath string, config config.Config, state *run.State) (io.ReadCloser, bool, error) {
+	if state.IsInMemory(filePath) {
+		content, size, err := state.ReadFileWithSize(filePath)
+		if err != nil {
+			return nil, false, err
+		}
+		truncated := isTruncated(size, config)
+		if !truncated && size > int64(len(content)) {
+			// only the first bytes of the file are held in memory
+			if content, err = state.ReadFile(filePath); err != nil {
+				return nil, false, err
+			}
+		}
+		if truncated {
+			if content, err = truncateLines(bytes.NewReader(content), config); err != nil {
+				return nil, false, err
+			}
+		}
+		return io.NopCloser(bytes.NewReader(content)), truncated, nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	fileStat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, false, err
+	}
+	if isTruncated(fileStat.Size(), config) {
+		defer file.Close()
+		content, err := truncateLines(file, config)
+		if err != nil {
+			return nil, false, err
+		}
+		return io.NopCloser(bytes.NewReader(content)), true, nil
+	}
+	return file, false, nil
+}
+
+// ReadFile returns the content of a file inside of an archive, from the source of the run,
+// or the file system if none is set.
+// Of a file larger than the MaxFileSize only the first LargeFileLines lines are returned, if set.
+func ReadFile(filePath string, config config.Config, state *run.State) ([]byte, error) {
+	file, err := Open(filePath, config, state)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+// isTruncated returns whether only the first lines of a file of the size are checked
+func isTruncated(size int64, config config.Config) bool {
+	return config.MaxFileSize > 0 && config.LargeFileLines > 0 && size > config.MaxFileSize
+}
+
+// truncateLines reads the first LargeFileLines lines, as far as they fit into the MaxFileSize.
+// A line which is cut off by the MaxFileSize is dropped, so the content always ends with a complete line.
+func truncateLines(reader io.Reader, config config.Config) ([]byte, error) {
+	bufferedReader := bufio.NewReader(io.LimitReader(reader, config.MaxFileSize))
+	var content []byte
+	for lines := 0; lines < config.LargeFileLines; lines++ {
+		line, err := bufferedReader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, line...)
+	}
+	return content, nil
+}
+
+// contentType returns the content type of a file, detected from its Content if it is held in memory
+func contentType(file File) (string, error) {
+	if file.Content == nil {
+		return GetContentType(file.Path)
+	}
+	if len(file.Content) == 0 {
+		return "", nil
+	}
+	return GetContentTypeBytes(bytes.NewReader(file.Content))
+}
+
 // GetContentTypeBytes returns the content type of a byte slice
 func GetContentTypeBytes(fileContent io.Reader) (string, error) {
 	mimeType, err := mimetype.DetectReader(fileContent)
@@ -1,7 +1,10 @@
 package files
 
 import (
+	"archive/zip"
+	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -13,6 +16,9 @@ import (
 
 	// x-release-please-start-major
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/events"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/git"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/run"
 	// x-release-please-end
 )
 
@@ -192,6 +198,27 @@ func TestAddToFiles(t *testing.T) {
 	}
 }
 
+func TestAddToFilesEvents(t *testing.T) {
+	var received []events.Event
+	configuration := config.NewConfig(nil)
+	configuration.Exclude = []string{"excluded"}
+	state := run.New()
+	state.Events = events.SubscriberFunc(func(event events.Event) {
+		received = append(received, event)
+	})
+
+	addToFiles(nil, "./files.go", *configuration, state)
+	addToFiles(nil, "./excluded.go", *configuration, state)
+
+	expected := []events.Event{
+		{Kind: events.FileDiscovered, FilePath: "./files.go"},
+		{Kind: events.FileSkipped, FilePath: "./excluded.go", Reason: "it is excluded"},
+	}
+	if !reflect.DeepEqual(received, expected) {
+		t.Errorf("expected the events %+v, got %+v", expected, received)
+	}
+}
+
 func TestGetFiles(t *testing.T) {
 	docsConfig := config.NewConfig(nil)
 	docsConfig.PassedFiles = []string{"./../../docs/"}
@@ -215,6 +242,439 @@ func TestGetFiles(t *testing.T) {
 	}
 }
 
+func TestDiscover(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for name, content := range map[string][]byte{
+		"a.txt":        []byte("a\n"),
+		"b.txt":        []byte("b\n"),
+		"image":        []byte("\x89PNG\r\n\x1a\n"),
+		"excluded.txt": []byte("excluded\n"),
+		"sub/c.txt":    []byte("c\n"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configuration := config.NewConfig(nil)
+	configuration.NoGit = true
+	configuration.Jobs = 2
+	configuration.Exclude = []string{"excluded"}
+
+	found := make(chan File)
+	go func() {
+		if err := Discover(context.Background(), *configuration, nil, found); err != nil {
+			t.Errorf("Discover(): expected nil, got %s", err.Error())
+		}
+	}()
+
+	indexes := make(map[int]bool)
+	var checked, skipped []string
+	for file := range found {
+		if indexes[file.Index] {
+			t.Errorf("Discover(): the index %d was sent twice", file.Index)
+		}
+		indexes[file.Index] = true
+		if file.Skipped {
+			skipped = append(skipped, filepath.Base(file.Path))
+		} else {
+			checked = append(checked, filepath.Base(file.Path))
+			// the files on the file system are read again when they are validated
+			if file.Size != 2 || file.ContentType != "text/plain" || file.Content != nil {
+				t.Errorf("Discover(): expected %s to be 2 bytes of text/plain without content, got %d bytes of %q and %q", file.Path, file.Size, file.ContentType, file.Content)
+			}
+		}
+	}
+	sort.Strings(checked)
+
+	// the excluded file gets no index, so the indexes are the numbers up to the number of files sent
+	for i := range len(indexes) {
+		if !indexes[i] {
+			t.Errorf("Discover(): expected the index %d to be sent, got %v", i, indexes)
+		}
+	}
+	if !reflect.DeepEqual(checked, []string{"a.txt", "b.txt", "c.txt"}) || !reflect.DeepEqual(skipped, []string{"image"}) {
+		t.Errorf("Discover(): expected the text files to be checked and the image to be skipped, got %v and %v", checked, skipped)
+	}
+
+	// a cancelled discovery returns the error of the context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	found = make(chan File)
+	go func() {
+		for range found {
+		}
+	}()
+	if err := Discover(ctx, *configuration, nil, found); !errors.Is(err, context.Canceled) {
+		t.Errorf("Discover(cancelled): expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestIsExcludedGlobs(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for name, content := range map[string]string{
+		".ecignore":      "generated/\n",
+		"sub/.ecignore":  "*.txt\n!keep.txt\n",
+		"sub/other/keep": "",
+	} {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configuration := config.NewConfig(nil)
+	configuration.ExcludeGlobs = []string{"vendor/*", "!vendor/ours/", "!*.min.js"}
+
+	tests := []struct {
+		filePath string
+		excluded bool
+	}{
+		{"vendor/lib/a.go", true},
+		{"vendor/a.go", true},
+		{"vendor/ours/a.go", false},
+		{"myvendor.go", false},
+		// a negated glob re-includes a file excluded by the default regular expressions
+		{"app.min.js", false},
+		{"app.min.css", true},
+		{"generated/a.go", true},
+		{"src/generated/a.go", true},
+		{"sub/a.txt", true},
+		{"sub/keep.txt", false},
+		{"sub/deeper/a.txt", true},
+		{"a.txt", false},
+	}
+
+	for _, tt := range tests {
+		excluded, err := IsExcluded(tt.filePath, *configuration)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if excluded != tt.excluded {
+			t.Errorf("IsExcluded(%s): expected %v, got %v", tt.filePath, tt.excluded, excluded)
+		}
+	}
+}
+
+func TestGetFilesNoGit(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for name, content := range map[string]string{
+		".git/HEAD":            "ref: refs/heads/main\n",
+		".git/info/exclude":    "*.local\n",
+		".gitignore":           "build/\n*.tmp\n",
+		"build/output.txt":     "ignored\n",
+		"checked.txt":          "checked\n",
+		"debug.tmp":            "ignored\n",
+		"settings.local":       "ignored\n",
+		"sub/.gitignore":       "!keep.tmp\n",
+		"sub/keep.tmp":         "re-included\n",
+		"sub/nested/build/out": "ignored\n",
+		// the .git file of a submodule points to its git directory
+		"sub/module/.git":    "gitdir: ../../.git/modules/module\n",
+		"sub/module/lib.txt": "checked\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configuration := config.NewConfig(nil)
+	configuration.NoGit = true
+	files, err := GetFiles(*configuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, file := range files {
+		files[i], _ = filepath.Rel(cwd, file)
+		files[i] = filepath.ToSlash(files[i])
+	}
+	sort.Strings(files)
+
+	expected := []string{".gitignore", "checked.txt", "sub/.gitignore", "sub/keep.tmp", "sub/module/lib.txt"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("GetFiles(no git): expected %v, got %v", expected, files)
+	}
+}
+
+func TestMaxFileSize(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for name, content := range map[string]string{
+		"small.txt": "small\n",
+		"large.txt": "first\nsecond\nthird line is long\n",
+	} {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configuration := config.NewConfig(nil)
+	configuration.PassedFiles = []string{"small.txt", "large.txt"}
+	configuration.MaxFileSize = 16
+	files, err := GetFiles(*configuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"small.txt"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("GetFiles(max file size): expected %v, got %v", expected, files)
+	}
+
+	configuration.LargeFileLines = 5
+	files, err = GetFiles(*configuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"small.txt", "large.txt"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("GetFiles(large file lines): expected %v, got %v", expected, files)
+	}
+
+	// the line cut off by the MaxFileSize is dropped
+	content, err := ReadFile("large.txt", *configuration, nil)
+	if err != nil || string(content) != "first\nsecond\n" {
+		t.Errorf("ReadFile(large file): expected %q, got %q, %v", "first\nsecond\n", content, err)
+	}
+
+	configuration.LargeFileLines = 1
+	content, err = ReadFile("large.txt", *configuration, nil)
+	if err != nil || string(content) != "first\n" {
+		t.Errorf("ReadFile(large file): expected %q, got %q, %v", "first\n", content, err)
+	}
+
+	content, err = ReadFile("small.txt", *configuration, nil)
+	if err != nil || string(content) != "small\n" {
+		t.Errorf("ReadFile(small file): expected %q, got %q, %v", "small\n", content, err)
+	}
+}
+
+func TestGetFilesArchive(t *testing.T) {
+	t.Chdir(t.TempDir())
+	file, err := os.Create("release.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zipWriter := zip.NewWriter(file)
+	for name, content := range map[string]string{
+		"src/main.go":   "package main\n",
+		"debug.log":     "excluded\n",
+		"logo.png":      "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+		".ecignore":     "generated/\n",
+		"generated/out": "ignored\n",
+	} {
+		writer, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	configuration := config.NewConfig(nil)
+	configuration.PassedFiles = []string{"release.zip"}
+	files, err := GetFiles(*configuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the excludes, .ecignore files and the content type apply to the paths inside the archive
+	expected := []string{"release.zip!/.ecignore", "release.zip!/src/main.go"}
+	sort.Strings(files)
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("GetFiles(archive): expected %v, got %v", expected, files)
+	}
+
+	// the entries are read while discovering them, and their content is kept for the validation
+	found := make(chan File)
+	go func() {
+		if err := Discover(context.Background(), *configuration, run.New(), found); err != nil {
+			t.Errorf("Discover(archive): expected nil, got %s", err.Error())
+		}
+	}()
+	for file := range found {
+		if file.Path == "release.zip!/src/main.go" && string(file.Content) != "package main\n" {
+			t.Errorf("Discover(archive): expected the content %q, got %q", "package main\n", file.Content)
+		}
+	}
+	discovered, err := DiscoverAll(context.Background(), *configuration, run.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, file := range discovered {
+		if file.Index != i || file.Content == nil || file.ContentType == "" {
+			t.Errorf("DiscoverAll(archive): expected the file %d with a ContentType and its content, got %+v", i, file)
+		}
+	}
+
+	content, err := ReadFile("release.zip!/src/main.go", *configuration, nil)
+	if err != nil || string(content) != "package main\n" {
+		t.Errorf("ReadFile(archive entry): expected %q, got %q, %v", "package main\n", content, err)
+	}
+
+	// only the first MaxFileSize bytes of the entries are held in memory, but the .ecignore applies as a whole
+	configuration.MaxFileSize = 8
+	configuration.LargeFileLines = 1
+	state := run.New()
+	state.ReloadEditorconfigs(configuration)
+	discovered, err = DiscoverAll(context.Background(), *configuration, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, file := range discovered {
+		paths = append(paths, file.Path)
+		if file.Path == "release.zip!/src/main.go" && (file.Size != int64(len("package main\n")) || !file.Truncated || file.Content == nil) {
+			t.Errorf("DiscoverAll(archive with MaxFileSize): expected the truncated main.go of %d bytes, got %+v", len("package main\n"), file)
+		}
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("DiscoverAll(archive with MaxFileSize): expected %v, got %v", expected, paths)
+	}
+}
+
+func TestGetFilesChangedSince(t *testing.T) {
+	t.Chdir(t.TempDir())
+	runGit := func(args ...string) {
+		t.Helper()
+		if _, err := git.Run(args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile := func(name string, content string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runGit("init", "--quiet")
+	writeFile("unchanged.txt", "unchanged\n")
+	writeFile("modified.txt", "before\n")
+	runGit("add", "--all")
+	runGit("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--message", "initial")
+	writeFile("modified.txt", "after\n")
+	writeFile("added.txt", "added\n")
+
+	configuration := config.NewConfig(nil)
+	configuration.ChangedSince = "HEAD"
+	files, err := GetFiles(*configuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	if expected := []string{"added.txt", "modified.txt"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("GetFiles(changed since HEAD): expected %v, got %v", expected, files)
+	}
+
+	configuration.PassedFiles = []string{"unchanged.txt", "modified.txt"}
+	files, err = GetFiles(*configuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"modified.txt"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("GetFiles(passed files changed since HEAD): expected %v, got %v", expected, files)
+	}
+
+	configuration.MergeBase = "HEAD"
+	if _, err := GetFiles(*configuration); err == nil {
+		t.Error("GetFiles(changed since and merge base): expected an error, got nil")
+	}
+
+	configuration.MergeBase = ""
+	configuration.ChangedSince = ""
+	configuration.NewLinesOnly = "HEAD"
+	files, err = GetFiles(*configuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"modified.txt"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("GetFiles(passed files with new lines since HEAD): expected %v, got %v", expected, files)
+	}
+}
+
+// mapSource is a source.Source of files in memory
+type mapSource map[string]string
+
+func (m mapSource) ListFiles() ([]string, error) {
+	filePaths := make([]string, 0, len(m))
+	for filePath := range m {
+		filePaths = append(filePaths, filePath)
+	}
+	sort.Strings(filePaths)
+	return filePaths, nil
+}
+
+func (m mapSource) ReadFile(filePath string) ([]byte, error) {
+	content, ok := m[filePath]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+// discoveredPaths returns the paths of the files which should be checked in the run
+func discoveredPaths(configuration config.Config, state *run.State) ([]string, error) {
+	discovered, err := DiscoverAll(context.Background(), configuration, state)
+	filePaths := make([]string, 0, len(discovered))
+	for _, file := range discovered {
+		filePaths = append(filePaths, file.Path)
+	}
+	return filePaths, err
+}
+
+func TestGetFilesFromSource(t *testing.T) {
+	configuration := config.NewConfig(nil)
+	state := run.New()
+	state.Source = mapSource{
+		"a.txt":          "text\n",
+		"sub/b.txt":      "text\n",
+		"sub/image.png":  "\x89PNG\r\n\x1a\n",
+		"subdir/c.txt":   "text\n",
+		"not-on-disk.md": "text\n",
+	}
+
+	files, err := discoveredPaths(*configuration, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"a.txt", "not-on-disk.md", "sub/b.txt", "subdir/c.txt"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("GetFiles(source): expected %v, got %v", expected, files)
+	}
+
+	configuration.PassedFiles = []string{"sub", "a.txt"}
+	files, err = discoveredPaths(*configuration, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"a.txt", "sub/b.txt"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("GetFiles(source with passed files): expected %v, got %v", expected, files)
+	}
+
+	configuration.ChangedSince = "HEAD"
+	if _, err := discoveredPaths(*configuration, state); err == nil {
+		t.Error("GetFiles(source changed since HEAD): expected an error, got nil")
+	}
+}
+
 func TestGetFilesGlobPattern(t *testing.T) {
 	globConfig := config.NewConfig(nil)
 	// Root-level markdown files (README.md, CHANGELOG.md, MAINTAINERS.md,
@@ -0,0 +1,342 @@
+// Package git contains functions which query a git repository through the git binary
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Run runs git with the given arguments in the current working directory and returns its output
+func Run(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			return nil, fmt.Errorf("running git %s: %w", strings.Join(args, " "), err)
+		}
+		return nil, fmt.Errorf("running git %s: %w: %s", strings.Join(args, " "), err, message)
+	}
+
+	return output, nil
+}
+
+// splitNul splits the NUL separated output of a git command run with -z
+func splitNul(output []byte) []string {
+	var entries []string
+	for entry := range strings.SplitSeq(string(output), "\x00") {
+		if entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// checkRef returns an error for a ref starting with a "-", which git would take for an option
+func checkRef(ref string) error {
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid git ref %q: it must not start with a \"-\"", ref)
+	}
+	return nil
+}
+
+// MergeBase returns the best common ancestor of HEAD and the given branch
+func MergeBase(branch string) (string, error) {
+	if err := checkRef(branch); err != nil {
+		return "", err
+	}
+	output, err := Run("merge-base", branch, "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	mergeBase := strings.TrimSpace(string(output))
+	if mergeBase == "" {
+		return "", errors.New("no merge base found for " + branch)
+	}
+	return mergeBase, nil
+}
+
+// ChangedFiles returns the paths of the files, relative to the current working directory,
+// which were added, modified, renamed or copied between the ref and the working tree,
+// including untracked files which are not ignored. Deleted files are not included.
+func ChangedFiles(ref string) ([]string, error) {
+	if err := checkRef(ref); err != nil {
+		return nil, err
+	}
+	output, err := Run("diff", "--name-only", "--relative", "-z", "--find-renames", "--diff-filter=d", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	changedFiles := splitNul(output)
+
+	untrackedFiles, err := UntrackedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(changedFiles, untrackedFiles...), nil
+}
+
+// UntrackedFiles returns the paths of the files, relative to the current working directory,
+// which are neither tracked nor ignored
+func UntrackedFiles() ([]string, error) {
+	output, err := Run("ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	return splitNul(output), nil
+}
+
+// StagedFiles returns the paths of the files, relative to the current working directory,
+// which were added, modified, renamed or copied in the index. Deleted files are not included.
+func StagedFiles() ([]string, error) {
+	output, err := Run("diff", "--cached", "--name-only", "--relative", "-z", "--find-renames", "--diff-filter=d", "--")
+	if err != nil {
+		return nil, err
+	}
+	return splitNul(output), nil
+}
+
+// LineRange is a range of line numbers, starting at 1, including Start and End
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// Contains returns whether the line number is within the range
+func (r LineRange) Contains(lineNumber int) bool {
+	return r.Start <= lineNumber && lineNumber <= r.End
+}
+
+// AddedLines returns the lines which were added or modified between the ref and the working tree,
+// by the path of the file relative to the current working directory.
+// Untracked files are not included, all their lines are new.
+func AddedLines(ref string) (map[string][]LineRange, error) {
+	if err := checkRef(ref); err != nil {
+		return nil, err
+	}
+	output, err := Run("diff", "--unified=0", "--relative", "--no-prefix", "--no-color", "--no-ext-diff", "--find-renames", "--diff-filter=d", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	return parseAddedLines(output)
+}
+
+// parseAddedLines parses the output of git diff --unified=0 --no-prefix
+func parseAddedLines(output []byte) (map[string][]LineRange, error) {
+	addedLines := make(map[string][]LineRange)
+
+	var filePath string
+	// a line starting with +++ is only a file name in the header, in a hunk it is an added line
+	inHeader := false
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(nil, 1024*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			filePath = ""
+			inHeader = true
+		case inHeader && strings.HasPrefix(line, "+++ "):
+			// git ends the path with a tab if it contains a space, a tab within a path is quoted
+			filePath = strings.TrimSuffix(strings.TrimPrefix(line, "+++ "), "\t")
+			if strings.HasPrefix(filePath, `"`) {
+				// git quotes paths with unusual characters like a C string
+				unquoted, err := strconv.Unquote(filePath)
+				if err != nil {
+					return nil, fmt.Errorf("parsing the path %s of git diff: %w", filePath, err)
+				}
+				filePath = unquoted
+			}
+			if filePath == "/dev/null" {
+				filePath = ""
+			}
+		case strings.HasPrefix(line, "@@ "):
+			inHeader = false
+			if filePath == "" {
+				continue
+			}
+			lineRange, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			if lineRange.End >= lineRange.Start {
+				addedLines[filePath] = append(addedLines[filePath], lineRange)
+			}
+		}
+	}
+
+	return addedLines, scanner.Err()
+}
+
+// parseHunkHeader returns the lines of the new file of a hunk header like "@@ -1,2 +3,4 @@"
+func parseHunkHeader(header string) (LineRange, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return LineRange{}, fmt.Errorf("invalid hunk header %q", header)
+	}
+
+	start, count, found := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+	startLine, err := strconv.Atoi(start)
+	if err != nil {
+		return LineRange{}, fmt.Errorf("invalid hunk header %q: %w", header, err)
+	}
+	lineCount := 1
+	if found {
+		lineCount, err = strconv.Atoi(count)
+		if err != nil {
+			return LineRange{}, fmt.Errorf("invalid hunk header %q: %w", header, err)
+		}
+	}
+
+	return LineRange{Start: startLine, End: startLine + lineCount - 1}, nil
+}
+
+// Show returns the content of the file in the ref, the path is relative to the current working directory.
+// An empty ref refers to the index, which holds the staged content.
+// The error wraps fs.ErrNotExist if the file does not exist in the ref.
+func Show(ref string, filePath string) ([]byte, error) {
+	if err := checkRef(ref); err != nil {
+		return nil, err
+	}
+	object := ref + ":./" + filePath
+	if _, err := Run("cat-file", "-e", object); err != nil {
+		if ref == "" {
+			ref = "the index"
+		}
+		return nil, fmt.Errorf("%s does not exist in %s: %w", filePath, ref, fs.ErrNotExist)
+	}
+	return Run("cat-file", "blob", object)
+}
+
+// Prefix returns the path of the current working directory relative to the root of the repository,
+// with a trailing slash, or an empty string in the root
+func Prefix() (string, error) {
+	output, err := Run("rev-parse", "--show-prefix")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// TreeEntry is an entry of a git tree, as listed by git ls-tree
+type TreeEntry struct {
+	Mode   string
+	Type   string
+	Object string
+	// Path is relative to the root of the repository
+	Path string
+}
+
+// ListTree returns all entries of the tree of the ref and its sub trees
+func ListTree(ref string) ([]TreeEntry, error) {
+	if err := checkRef(ref); err != nil {
+		return nil, err
+	}
+	output, err := Run("ls-tree", "-r", "-z", "--full-tree", ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []TreeEntry
+	for _, line := range splitNul(output) {
+		// <mode> SP <type> SP <object> TAB <path>
+		info, entryPath, found := strings.Cut(line, "\t")
+		fields := strings.Fields(info)
+		if !found || len(fields) != 3 {
+			return nil, fmt.Errorf("invalid git ls-tree entry %q", line)
+		}
+		entries = append(entries, TreeEntry{Mode: fields[0], Type: fields[1], Object: fields[2], Path: entryPath})
+	}
+
+	return entries, nil
+}
+
+// CatFile reads objects through a single git cat-file --batch process,
+// which is much faster than starting git for each object. It is safe for concurrent use.
+type CatFile struct {
+	lock   sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// NewCatFile starts git cat-file --batch in the current working directory
+func NewCatFile() (*CatFile, error) {
+	cmd := exec.Command("git", "cat-file", "--batch")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("running git cat-file --batch: %w", err)
+	}
+
+	return &CatFile{cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout)}, nil
+}
+
+// Read returns the content of an object.
+// The error wraps fs.ErrNotExist if the object does not exist.
+func (c *CatFile) Read(object string) ([]byte, error) {
+	if strings.ContainsAny(object, "\n") {
+		return nil, fmt.Errorf("invalid object name %q", object)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, err := io.WriteString(c.stdin, object+"\n"); err != nil {
+		return nil, fmt.Errorf("reading %s with git cat-file --batch: %w", object, err)
+	}
+
+	// <object> SP <type> SP <size> LF, or <object> SP missing LF
+	header, err := c.stdout.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("reading %s with git cat-file --batch: %w", object, err)
+	}
+	// the object name may contain spaces, so only the end of the header is reliable
+	if strings.HasSuffix(header, " missing\n") {
+		return nil, fmt.Errorf("object %s: %w", object, fs.ErrNotExist)
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("reading %s with git cat-file --batch: unexpected header %q", object, header)
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("reading %s with git cat-file --batch: unexpected header %q", object, header)
+	}
+
+	// the content is followed by a LF
+	content := make([]byte, size+1)
+	if _, err := io.ReadFull(c.stdout, content); err != nil {
+		return nil, fmt.Errorf("reading %s with git cat-file --batch: %w", object, err)
+	}
+
+	return content[:size], nil
+}
+
+// Close stops the git process
+func (c *CatFile) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.stdin.Close()
+	return c.cmd.Wait()
+}
@@ -0,0 +1,297 @@
+package git
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// initRepository creates a git repository in a temporary directory, changes into it
+// and commits the given files
+func initRepository(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	t.Chdir(t.TempDir())
+	mustRun(t, "init", "--quiet", "--initial-branch=main")
+	writeFiles(t, files)
+	commit(t, "initial")
+}
+
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func commit(t *testing.T, message string) {
+	t.Helper()
+
+	mustRun(t, "add", "--all")
+	mustRun(t, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--message", message)
+}
+
+func mustRun(t *testing.T, args ...string) string {
+	t.Helper()
+
+	output, err := Run(args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(output)
+}
+
+func TestRunReportsStderr(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	_, err := Run("rev-parse", "HEAD")
+	if err == nil {
+		t.Fatal("expected an error outside of a repository")
+	}
+	if !strings.Contains(err.Error(), "not a git repository") {
+		t.Errorf("expected the error to contain the message of git, got %q", err)
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	initRepository(t, map[string]string{
+		"unchanged.txt":    "unchanged\n",
+		"modified.txt":     "before\n",
+		"deleted.txt":      "deleted\n",
+		"renamed.txt":      "a file long enough to be detected as renamed\n",
+		"sub/modified.txt": "before\n",
+	})
+	base := mustRun(t, "rev-parse", "HEAD")[:40]
+
+	writeFiles(t, map[string]string{
+		"modified.txt": "after\n",
+		"added.txt":    "added\n",
+	})
+	if err := os.Remove("deleted.txt"); err != nil {
+		t.Fatal(err)
+	}
+	mustRun(t, "mv", "renamed.txt", "moved.txt")
+	commit(t, "second")
+
+	writeFiles(t, map[string]string{
+		"sub/modified.txt": "after, but not committed\n",
+		"untracked.txt":    "untracked\n",
+	})
+
+	changedFiles, err := ChangedFiles(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(changedFiles)
+
+	expected := []string{"added.txt", "modified.txt", "moved.txt", "sub/modified.txt", "untracked.txt"}
+	if !reflect.DeepEqual(changedFiles, expected) {
+		t.Errorf("expected %v, got %v", expected, changedFiles)
+	}
+
+	// paths are relative to the current working directory, like the ones of git ls-files
+	t.Chdir("sub")
+	changedFiles, err = ChangedFiles(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"modified.txt"}; !reflect.DeepEqual(changedFiles, expected) {
+		t.Errorf("expected %v, got %v", expected, changedFiles)
+	}
+
+	if _, err := ChangedFiles("nonexistent-ref"); err == nil {
+		t.Error("expected an error for a nonexistent ref")
+	}
+}
+
+func TestMergeBase(t *testing.T) {
+	initRepository(t, map[string]string{"a.txt": "a\n"})
+	base := mustRun(t, "rev-parse", "HEAD")[:40]
+
+	mustRun(t, "checkout", "--quiet", "-b", "feature")
+	writeFiles(t, map[string]string{"b.txt": "b\n"})
+	commit(t, "feature")
+
+	mergeBase, err := MergeBase("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mergeBase != base {
+		t.Errorf("expected merge base %s, got %s", base, mergeBase)
+	}
+
+	if _, err := MergeBase("nonexistent-branch"); err == nil {
+		t.Error("expected an error for a nonexistent branch")
+	}
+}
+
+func TestRefsStartingWithDash(t *testing.T) {
+	initRepository(t, map[string]string{"a.txt": "a\n"})
+
+	// git would take the refs for options, --output writes the diff to a file
+	ref := "--output=written.txt"
+	if _, err := ChangedFiles(ref); err == nil {
+		t.Error("ChangedFiles: expected an error for a ref starting with a dash")
+	}
+	if _, err := AddedLines(ref); err == nil {
+		t.Error("AddedLines: expected an error for a ref starting with a dash")
+	}
+	if _, err := MergeBase(ref); err == nil {
+		t.Error("MergeBase: expected an error for a ref starting with a dash")
+	}
+	if _, err := ListTree(ref); err == nil {
+		t.Error("ListTree: expected an error for a ref starting with a dash")
+	}
+	if _, err := os.Stat("written.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected git not to write a file, got %v", err)
+	}
+}
+
+func TestParseAddedLines(t *testing.T) {
+	output := []byte(`diff --git a.txt a.txt
+index 1111111..2222222 100644
+--- a.txt
++++ a.txt
+@@ -1 +1 @@
+-before
++after
+@@ -5,0 +6,2 @@ context
+++++ an added line looking like a header
++another added line
+@@ -9,2 +10,0 @@
+-removed
+-removed
+diff --git "t\303\244b.txt" "t\303\244b.txt"
+--- "t\303\244b.txt"
++++ "t\303\244b.txt"
+@@ -3,2 +3,3 @@
+diff --git x y.txt x y.txt
+--- x y.txt` + "\t" + `
++++ x y.txt` + "\t" + `
+@@ -1,0 +2 @@
+`)
+
+	addedLines, err := parseAddedLines(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]LineRange{
+		"a.txt":   {{Start: 1, End: 1}, {Start: 6, End: 7}},
+		"täb.txt": {{Start: 3, End: 5}},
+		"x y.txt": {{Start: 2, End: 2}},
+	}
+	if !reflect.DeepEqual(addedLines, expected) {
+		t.Errorf("expected %v, got %v", expected, addedLines)
+	}
+
+	if _, err := parseAddedLines([]byte("diff --git a b\n+++ b\n@@ -1 +x @@\n")); err == nil {
+		t.Error("expected an error for an invalid hunk header")
+	}
+}
+
+func TestAddedLines(t *testing.T) {
+	initRepository(t, map[string]string{"a.txt": "1\n2\n3\n", "with space.txt": "1\n", `with "quote".txt`: "1\n"})
+	writeFiles(t, map[string]string{
+		"a.txt":            "1\nchanged\n3\nadded\n",
+		"with space.txt":   "1\nadded\n",
+		`with "quote".txt`: "1\nadded\n",
+		"untracked.txt":    "untracked\n",
+	})
+
+	addedLines, err := AddedLines("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]LineRange{
+		"a.txt":            {{Start: 2, End: 2}, {Start: 4, End: 4}},
+		"with space.txt":   {{Start: 2, End: 2}},
+		`with "quote".txt`: {{Start: 2, End: 2}},
+	}
+	if !reflect.DeepEqual(addedLines, expected) {
+		t.Errorf("expected %v, got %v", expected, addedLines)
+	}
+}
+
+func TestShow(t *testing.T) {
+	initRepository(t, map[string]string{"sub/a.txt": "committed\n"})
+	writeFiles(t, map[string]string{"sub/a.txt": "modified\n"})
+	t.Chdir("sub")
+
+	content, err := Show("HEAD", "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "committed\n" {
+		t.Errorf("expected the committed content, got %q", content)
+	}
+
+	if _, err := Show("HEAD", "nonexistent.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist for a nonexistent file, got %v", err)
+	}
+}
+
+func TestListTreeAndCatFile(t *testing.T) {
+	initRepository(t, map[string]string{
+		"a.txt":     "a\n",
+		"sub/b.txt": "b\n",
+	})
+	writeFiles(t, map[string]string{"a.txt": "not committed\n"})
+
+	entries, err := ListTree("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Path != "a.txt" || entries[1].Path != "sub/b.txt" || entries[0].Type != "blob" {
+		t.Fatalf("expected the blobs a.txt and sub/b.txt, got %+v", entries)
+	}
+
+	catFile, err := NewCatFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer catFile.Close()
+
+	// the process is reused for each object
+	for _, entry := range []TreeEntry{entries[0], entries[1], entries[0]} {
+		content, err := catFile.Read(entry.Object)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := map[string]string{"a.txt": "a\n", "sub/b.txt": "b\n"}[entry.Path]; string(content) != expected {
+			t.Errorf("expected %q, got %q", expected, content)
+		}
+	}
+
+	if _, err := catFile.Read("0000000000000000000000000000000000000000"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist for a missing object, got %v", err)
+	}
+	if _, err := catFile.Read("HEAD:sub/my file.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist for a missing path with spaces, got %v", err)
+	}
+	// the process is still usable afterwards
+	if content, err := catFile.Read(entries[0].Object); err != nil || string(content) != "a\n" {
+		t.Errorf("expected %q after a missing object, got %q, %v", "a\n", content, err)
+	}
+
+	t.Chdir("sub")
+	prefix, err := Prefix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prefix != "sub/" {
+		t.Errorf("expected the prefix sub/, got %q", prefix)
+	}
+}
@@ -0,0 +1,345 @@
+// Package gitignore matches paths against the patterns of .gitignore files without the git binary
+package gitignore
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+)
+
+// pattern is a single pattern of a .gitignore file
+type pattern struct {
+	// base is the directory of the .gitignore file, or empty for the root
+	base string
+	// negate re-includes paths which a previous pattern excluded
+	negate bool
+	// dirOnly only matches directories
+	dirOnly bool
+	// basename matches the name of a file or directory at any depth instead of the path relative to base
+	basename bool
+	regexp   *regexp.Regexp
+}
+
+// parsePattern parses a line of a .gitignore file in the directory base.
+// It returns false for blank lines and comments.
+func parsePattern(line string, base string) (pattern, bool) {
+	line = trimTrailingSpaces(strings.TrimSuffix(line, "\r"))
+	if line == "" || strings.HasPrefix(line, "#") {
+		return pattern{}, false
+	}
+
+	p := pattern{base: strings.Trim(path.Clean("/"+filepath.ToSlash(base)), "/")}
+
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return pattern{}, false
+	}
+
+	// a pattern without a slash, apart from a trailing one, matches at any depth
+	p.basename = !strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	re, err := regexp.Compile("^" + translate(line) + "$")
+	if err != nil {
+		return pattern{}, false
+	}
+	p.regexp = re
+
+	return p, true
+}
+
+// trimTrailingSpaces removes trailing spaces which are not escaped with a backslash
+func trimTrailingSpaces(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// translate converts a gitignore glob into a regular expression
+func translate(glob string) string {
+	var result strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**") && (i == 0 || glob[i-1] == '/') && (i+2 == len(glob) || glob[i+2] == '/') {
+				switch {
+				case i+2 == len(glob):
+					// a trailing /** matches everything inside
+					result.WriteString(".*")
+				default:
+					// a leading **/ or /**/ matches zero or more directories
+					result.WriteString("(?:.*/)?")
+					i++
+				}
+				i++
+				continue
+			}
+			result.WriteString("[^/]*")
+		case '?':
+			result.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end == -1 {
+				result.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			result.WriteString("[" + strings.ReplaceAll(class, "\\", "\\\\") + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+			}
+			result.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			result.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return result.String()
+}
+
+// match returns whether the pattern matches the path.
+// Only the path itself is matched, a pattern matching one of its parent directories is not considered.
+func (p pattern) match(relativePath string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+
+	if p.base != "" {
+		if !strings.HasPrefix(relativePath, p.base+"/") {
+			return false
+		}
+		relativePath = relativePath[len(p.base)+1:]
+	}
+
+	if p.basename {
+		relativePath = path.Base(relativePath)
+	}
+	return p.regexp.MatchString(relativePath)
+}
+
+// Matcher decides whether paths are ignored by a list of patterns, where the last matching pattern wins.
+// Paths and the directories of .gitignore files are relative to the directory of the matcher.
+type Matcher struct {
+	// prefix is the directory of the matcher relative to the directory the bases of the patterns are relative to
+	prefix   string
+	patterns []pattern
+}
+
+// AddPatterns adds the patterns of the content of a .gitignore file in the directory base.
+// They take precedence over the ones added before.
+func (m *Matcher) AddPatterns(content []byte, base string) {
+	base = path.Join(m.prefix, filepath.ToSlash(base))
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if p, ok := parsePattern(scanner.Text(), base); ok {
+			m.patterns = append(m.patterns, p)
+		}
+	}
+}
+
+// AddFile adds the patterns of a file in the directory base.
+// A file which does not exist is skipped.
+func (m *Matcher) AddFile(filePath string, base string) error {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		// a path through a file rather than a directory does not exist either
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) {
+			return nil
+		}
+		return err
+	}
+	m.AddPatterns(content, base)
+	return nil
+}
+
+// match returns whether the path is ignored, and whether any pattern matched it at all
+func (m *Matcher) match(relativePath string, isDir bool) (bool, bool) {
+	relativePath = path.Join(m.prefix, filepath.ToSlash(relativePath))
+	for i := len(m.patterns) - 1; i >= 0; i-- {
+		if m.patterns[i].match(relativePath, isDir) {
+			return !m.patterns[i].negate, true
+		}
+	}
+	return false, false
+}
+
+// Match returns whether the path is ignored.
+// Only the path itself is matched, which is enough when walking a directory
+// and not descending into ignored directories.
+func (m *Matcher) Match(relativePath string, isDir bool) bool {
+	ignored, _ := m.match(relativePath, isDir)
+	return ignored
+}
+
+// Lookup returns whether the path or one of its parent directories is ignored,
+// and whether any pattern matched it at all, so a negated pattern can override other excludes.
+// Like git, a file in an ignored directory cannot be re-included.
+func (m *Matcher) Lookup(relativePath string, isDir bool) (bool, bool) {
+	relativePath = path.Clean(filepath.ToSlash(relativePath))
+	for i := 0; i < len(relativePath); i++ {
+		if relativePath[i] != '/' {
+			continue
+		}
+		if ignored, _ := m.match(relativePath[:i], true); ignored {
+			return true, true
+		}
+	}
+	return m.match(relativePath, isDir)
+}
+
+// Ignored returns whether the path or one of its parent directories is ignored.
+// Like git, a file in an ignored directory cannot be re-included.
+func (m *Matcher) Ignored(relativePath string, isDir bool) bool {
+	ignored, _ := m.Lookup(relativePath, isDir)
+	return ignored
+}
+
+// DirectoryMatcher is a Matcher which reads a file of patterns, like .gitignore,
+// of each directory the first time a path within it is looked up
+type DirectoryMatcher struct {
+	Matcher
+	fileName string
+	readFile func(filePath string) ([]byte, error)
+	loaded   map[string]bool
+}
+
+// NewDirectoryMatcher creates a DirectoryMatcher reading the files named fileName with readFile
+func NewDirectoryMatcher(fileName string, readFile func(filePath string) ([]byte, error)) *DirectoryMatcher {
+	return &DirectoryMatcher{fileName: fileName, readFile: readFile, loaded: make(map[string]bool)}
+}
+
+// Lookup reads the files of the directories of the path, then returns whether the path
+// or one of its parent directories is ignored, and whether any pattern matched it at all
+func (d *DirectoryMatcher) Lookup(relativePath string, isDir bool) (bool, bool) {
+	relativePath = path.Clean(filepath.ToSlash(relativePath))
+	directories := []string{"."}
+	for i := 0; i < len(relativePath); i++ {
+		if relativePath[i] == '/' {
+			directories = append(directories, relativePath[:i])
+		}
+	}
+
+	for _, directory := range directories {
+		if d.loaded[directory] {
+			continue
+		}
+		d.loaded[directory] = true
+		if content, err := d.readFile(path.Join(directory, d.fileName)); err == nil {
+			d.AddPatterns(content, directory)
+		}
+	}
+
+	return d.Matcher.Lookup(relativePath, isDir)
+}
+
+// RepositoryRoot returns the closest directory containing dir which contains .git,
+// which is a directory in a repository and a file in a worktree or submodule
+func RepositoryRoot(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// ForDirectory returns a matcher for walking dir.
+// Within a repository it contains the patterns of .git/info/exclude and of the .gitignore files
+// of the parent directories of dir up to the root of the repository, but not those of dir and
+// its sub directories, which are to be added with AddFile while walking.
+func ForDirectory(dir string) (*Matcher, error) {
+	root, ok := RepositoryRoot(dir)
+	if !ok {
+		return &Matcher{}, nil
+	}
+	absoluteDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := filepath.Rel(root, absoluteDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// the patterns of the parent directories are relative to the root of the repository
+	matcher := &Matcher{}
+	if err := matcher.AddFile(excludeFile(root), ""); err != nil {
+		return nil, err
+	}
+	base := ""
+	for _, directory := range strings.Split(filepath.ToSlash(prefix), "/") {
+		if directory == "." {
+			break
+		}
+		if err := matcher.AddFile(filepath.Join(root, filepath.FromSlash(base), ".gitignore"), base); err != nil {
+			return nil, err
+		}
+		base = path.Join(base, directory)
+	}
+	matcher.prefix = base
+
+	return matcher, nil
+}
+
+// ExcludeFile returns the path of the info/exclude file of the repository containing dir,
+// and false if dir is not in a repository
+func ExcludeFile(dir string) (string, bool) {
+	root, ok := RepositoryRoot(dir)
+	if !ok {
+		return "", false
+	}
+	return excludeFile(root), true
+}
+
+// excludeFile returns the path of the info/exclude file of the repository at the root.
+// In a worktree or submodule .git is a file with the path of the git directory in a "gitdir:" line,
+// and a worktree shares the info/exclude file of the repository given by the commondir file of its git directory.
+func excludeFile(root string) string {
+	gitDir := filepath.Join(root, ".git")
+	// reading .git fails if it is a directory
+	if content, err := os.ReadFile(gitDir); err == nil {
+		if dir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:"); ok {
+			gitDir = resolvePath(root, strings.TrimSpace(dir))
+		}
+	}
+	if content, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		gitDir = resolvePath(gitDir, strings.TrimSpace(string(content)))
+	}
+	return filepath.Join(gitDir, "info", "exclude")
+}
+
+// resolvePath returns the path, which may be relative to the directory
+func resolvePath(dir string, filePath string) string {
+	filePath = filepath.FromSlash(filePath)
+	if filepath.IsAbs(filePath) {
+		return filePath
+	}
+	return filepath.Join(dir, filePath)
+}
@@ -0,0 +1,176 @@
+package gitignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	matcher := &Matcher{}
+	matcher.AddPatterns([]byte(`# a comment
+*.log
+!important.log
+build/
+/root-only.txt
+docs/*.md
+a/**/z
+**/generated
+vendor/**
+\#hash
+[!a-c]x.txt
+`), "")
+	// an escaped trailing space is kept
+	matcher.AddPatterns([]byte("trailing\\ \n"), "")
+	matcher.AddPatterns([]byte("*.tmp\n!keep.log\n"), "sub")
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"app.log", false, true},
+		{"deep/nested/app.log", false, true},
+		{"important.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"src/build", true, true},
+		{"root-only.txt", false, true},
+		{"sub/root-only.txt", false, false},
+		{"docs/readme.md", false, true},
+		{"docs/deep/readme.md", false, false},
+		{"a/z", false, true},
+		{"a/b/c/z", false, true},
+		{"generated", true, true},
+		{"x/y/generated", false, true},
+		{"vendor/lib/file.go", false, true},
+		{"myvendor.go", false, false},
+		{"#hash", false, true},
+		{"# a comment", false, false},
+		{"trailing ", false, true},
+		{"dx.txt", false, true},
+		{"ax.txt", false, false},
+		{"sub/file.tmp", false, true},
+		{"file.tmp", false, false},
+		{"sub/keep.log", false, false},
+		{"keep.log", false, true},
+	}
+
+	for _, test := range tests {
+		if ignored := matcher.Match(test.path, test.isDir); ignored != test.ignored {
+			t.Errorf("Match(%q, %v): expected %v, got %v", test.path, test.isDir, test.ignored, ignored)
+		}
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	matcher := &Matcher{}
+	matcher.AddPatterns([]byte("build/\n!build/keep.txt\n"), "")
+
+	// a file in an ignored directory cannot be re-included
+	if !matcher.Ignored("build/keep.txt", false) {
+		t.Error("expected build/keep.txt to be ignored with its directory")
+	}
+	if matcher.Ignored("src/main.go", false) {
+		t.Error("expected src/main.go not to be ignored")
+	}
+}
+
+func TestForDirectory(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		".git/info/exclude": "*.local\n",
+		".gitignore":        "*.log\n/sub/direct.txt\n",
+		"sub/.gitignore":    "!keep.log\n",
+	} {
+		filePath := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	matcher, err := ForDirectory(filepath.Join(root, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the patterns of the parent directories apply relative to sub
+	for path, expected := range map[string]bool{
+		"app.log":    true,
+		"direct.txt": true,
+		"x.local":    true,
+		"keep.log":   true,
+		"other.txt":  false,
+	} {
+		if ignored := matcher.Match(path, false); ignored != expected {
+			t.Errorf("Match(%q): expected %v, got %v", path, expected, ignored)
+		}
+	}
+
+	// the .gitignore of sub itself is added while walking
+	if err := matcher.AddFile(filepath.Join(root, "sub", ".gitignore"), "."); err != nil {
+		t.Fatal(err)
+	}
+	if matcher.Match("keep.log", false) {
+		t.Error("expected keep.log to be re-included by sub/.gitignore")
+	}
+
+	outside, err := ForDirectory(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outside.Match("app.log", false) {
+		t.Error("expected nothing to be ignored outside of a repository")
+	}
+}
+
+func TestForDirectoryWithGitFile(t *testing.T) {
+	dir := t.TempDir()
+	// a worktree of a repository, whose git directory is within the one of the repository
+	for name, content := range map[string]string{
+		"main/.git/info/exclude":                "*.local\n",
+		"main/.git/worktrees/feature/commondir": "../..\n",
+		"feature/.git":                          "gitdir: ../main/.git/worktrees/feature\n",
+		"feature/.gitignore":                    "*.log\n",
+		// a submodule, whose git directory has an info/exclude file of its own
+		"main/.git/modules/lib/info/exclude": "*.tmp\n",
+		"main/lib/.git":                      "gitdir: ../.git/modules/lib\n",
+		// a .git file which cannot be read as one
+		"broken/.git": "not a gitdir\n",
+	} {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "feature", "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		dir     string
+		ignored string
+	}{
+		{"feature/sub", "x.local"},
+		{"feature/sub", "app.log"},
+		{"main/lib", "x.tmp"},
+	} {
+		matcher, err := ForDirectory(filepath.Join(dir, filepath.FromSlash(tt.dir)))
+		if err != nil {
+			t.Fatalf("ForDirectory(%s): expected nil, got %v", tt.dir, err)
+		}
+		if !matcher.Match(tt.ignored, false) {
+			t.Errorf("ForDirectory(%s): expected %s to be ignored", tt.dir, tt.ignored)
+		}
+	}
+
+	if _, err := ForDirectory(filepath.Join(dir, "broken")); err != nil {
+		t.Errorf("ForDirectory(broken): expected nil, got %v", err)
+	}
+}
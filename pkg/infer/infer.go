@@ -0,0 +1,360 @@
+// Package infer proposes an .editorconfig which matches the habits of an existing codebase
+package infer
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/encoding"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/files"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/validation"
+	// x-release-please-end
+)
+
+// DefaultMaxLineLengthPercentile is the percentile of the per file longest lines
+// which is proposed as max_line_length
+const DefaultMaxLineLengthPercentile = 95.0
+
+// maxIndentSize is the biggest indentation step which is considered to be an indent_size
+const maxIndentSize = 8
+
+// candidateCharsets are the charsets which can be proposed, in order of preference
+var candidateCharsets = []string{
+	encoding.CharsetUTF8,
+	encoding.CharsetUTF8BOM,
+	encoding.CharsetUTF16BE,
+	encoding.CharsetUTF16LE,
+	encoding.CharsetLatin1,
+}
+
+// FileStats holds what was measured in a single file
+// Empty strings and zero values mean the file gave no hint about that property
+type FileStats struct {
+	IndentStyle     string
+	IndentSize      int
+	EndOfLine       string
+	FinalNewline    string
+	Charset         string
+	MaxLineLength   int
+	HasLineContents bool
+}
+
+// Property is a single key value pair of an .editorconfig section
+type Property struct {
+	Key   string
+	Value string
+}
+
+// Section is a proposed .editorconfig section together with how well the codebase already fits it
+type Section struct {
+	Glob            string
+	Properties      []Property
+	FileCount       int
+	ConformingCount int
+}
+
+// ConformingPercentage returns the percentage of files which already conform to the section
+func (s Section) ConformingPercentage() float64 {
+	if s.FileCount == 0 {
+		return 100
+	}
+	return 100 * float64(s.ConformingCount) / float64(s.FileCount)
+}
+
+// Definition returns the section as an editorconfig definition
+func (s Section) Definition() *editorconfig.Definition {
+	def := &editorconfig.Definition{Raw: make(map[string]string, len(s.Properties))}
+	for _, property := range s.Properties {
+		def.Raw[property.Key] = property.Value
+	}
+	return def
+}
+
+// Proposal is a proposed .editorconfig
+type Proposal struct {
+	Sections []Section
+}
+
+// FileCount returns the amount of files the proposal was inferred from
+func (p Proposal) FileCount() int {
+	count := 0
+	for _, section := range p.Sections {
+		count += section.FileCount
+	}
+	return count
+}
+
+// ConformingCount returns the amount of files which already conform to the proposal
+func (p Proposal) ConformingCount() int {
+	count := 0
+	for _, section := range p.Sections {
+		count += section.ConformingCount
+	}
+	return count
+}
+
+// String returns the proposal in the .editorconfig format
+func (p Proposal) String() string {
+	var builder strings.Builder
+
+	fileCount := p.FileCount()
+	conformingPercentage := 100.0
+	if fileCount > 0 {
+		conformingPercentage = 100 * float64(p.ConformingCount()) / float64(fileCount)
+	}
+
+	fmt.Fprintf(&builder, "# proposed by editorconfig-checker from %d files, %.1f%% already conforming\n", fileCount, conformingPercentage)
+	builder.WriteString("root = true\n")
+
+	for _, section := range p.Sections {
+		builder.WriteString("\n")
+		fmt.Fprintf(&builder, "# %d files, %.1f%% already conforming\n", section.FileCount, section.ConformingPercentage())
+		fmt.Fprintf(&builder, "[%s]\n", section.Glob)
+		for _, property := range section.Properties {
+			fmt.Fprintf(&builder, "%s = %s\n", property.Key, property.Value)
+		}
+	}
+
+	return builder.String()
+}
+
+// SectionGlob returns the glob of the section a file is grouped into,
+// which is its extension or its name if it has none
+func SectionGlob(filePath string) string {
+	name := filepath.Base(filePath)
+	extension := filepath.Ext(name)
+	if extension == "" || extension == name {
+		return name
+	}
+	return "*" + extension
+}
+
+// Measure measures the properties of the content of a single file
+func Measure(rawFileContent []byte) FileStats {
+	var stats FileStats
+	if len(rawFileContent) == 0 {
+		return stats
+	}
+
+	fileContent, charset, err := encoding.Decode(rawFileContent)
+	if err == nil && charset != encoding.BinaryData && charset != encoding.UnknownEncoding {
+		for _, candidate := range candidateCharsets {
+			if encoding.CharsetsMatch(charset, candidate) {
+				stats.Charset = candidate
+				break
+			}
+		}
+	}
+
+	stats.EndOfLine = measureEndOfLine(fileContent)
+	if stats.EndOfLine != "" {
+		if strings.HasSuffix(fileContent, "\n") || strings.HasSuffix(fileContent, "\r") {
+			stats.FinalNewline = "true"
+		} else {
+			stats.FinalNewline = "false"
+		}
+	}
+
+	var tabLines, spaceLines, previousIndent int
+	indentSteps := make(map[int]int)
+
+	for _, line := range files.ReadLines(fileContent) {
+		stats.HasLineContents = true
+
+		length := utf8.RuneCountInString(strings.TrimPrefix(line, "\uFEFF"))
+		if length > stats.MaxLineLength {
+			stats.MaxLineLength = length
+		}
+
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			// blank lines say nothing about the indentation
+			continue
+		}
+
+		switch line[0] {
+		case '\t':
+			tabLines++
+			continue
+		case ' ':
+			if !strings.HasPrefix(trimmed, "*") {
+				spaceLines++
+			}
+		}
+
+		// block comment continuations are indented by one additional space
+		if strings.HasPrefix(trimmed, "*") || strings.ContainsRune(line[:len(line)-len(trimmed)], '\t') {
+			continue
+		}
+
+		indent := len(line) - len(trimmed)
+		if step := indent - previousIndent; step > 0 && step <= maxIndentSize {
+			indentSteps[step]++
+		}
+		previousIndent = indent
+	}
+
+	switch {
+	case tabLines > spaceLines:
+		stats.IndentStyle = "tab"
+	case spaceLines > tabLines:
+		stats.IndentStyle = "space"
+		stats.IndentSize = mostCommonStep(indentSteps)
+	}
+
+	return stats
+}
+
+// measureEndOfLine returns the most used line ending of the content
+func measureEndOfLine(fileContent string) string {
+	crlf := strings.Count(fileContent, "\r\n")
+	lf := strings.Count(fileContent, "\n") - crlf
+	cr := strings.Count(fileContent, "\r") - crlf
+
+	switch {
+	case lf == 0 && cr == 0 && crlf == 0:
+		return ""
+	case lf >= crlf && lf >= cr:
+		return "lf"
+	case crlf >= cr:
+		return "crlf"
+	default:
+		return "cr"
+	}
+}
+
+// mostCommonStep returns the most common indentation step,
+// preferring the bigger step if two are equally common
+func mostCommonStep(indentSteps map[int]int) int {
+	step, count := 0, 0
+	for candidate := maxIndentSize; candidate > 0; candidate-- {
+		if indentSteps[candidate] > count {
+			step, count = candidate, indentSteps[candidate]
+		}
+	}
+	return step
+}
+
+// mostCommon returns the most common non empty value, ties are broken alphabetically
+func mostCommon(values []string) string {
+	counts := make(map[string]int)
+	for _, value := range values {
+		if value != "" {
+			counts[value]++
+		}
+	}
+
+	best, bestCount := "", 0
+	for value, count := range counts {
+		if count > bestCount || (count == bestCount && value < best) {
+			best, bestCount = value, count
+		}
+	}
+	return best
+}
+
+// percentile returns the given percentile of the values using the nearest-rank method
+func percentile(values []int, p float64) int {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	rank = max(1, min(rank, len(sorted)))
+	return sorted[rank-1]
+}
+
+// propose builds the section of a group of files from their measurements
+func propose(glob string, stats []FileStats, maxLineLengthPercentile float64) Section {
+	var indentStyles, endOfLines, finalNewlines, charsets []string
+	var lineLengths []int
+	indentSizes := make(map[string][]string)
+
+	for _, fileStats := range stats {
+		indentStyles = append(indentStyles, fileStats.IndentStyle)
+		endOfLines = append(endOfLines, fileStats.EndOfLine)
+		finalNewlines = append(finalNewlines, fileStats.FinalNewline)
+		charsets = append(charsets, fileStats.Charset)
+		if fileStats.IndentSize > 0 {
+			indentSizes[fileStats.IndentStyle] = append(indentSizes[fileStats.IndentStyle], strconv.Itoa(fileStats.IndentSize))
+		}
+		if fileStats.HasLineContents {
+			lineLengths = append(lineLengths, fileStats.MaxLineLength)
+		}
+	}
+
+	section := Section{Glob: glob, FileCount: len(stats)}
+	addProperty := func(key string, value string) {
+		if value != "" {
+			section.Properties = append(section.Properties, Property{Key: key, Value: value})
+		}
+	}
+
+	indentStyle := mostCommon(indentStyles)
+	addProperty("indent_style", indentStyle)
+	if indentStyle == "space" {
+		addProperty("indent_size", mostCommon(indentSizes[indentStyle]))
+	}
+	addProperty("end_of_line", mostCommon(endOfLines))
+	addProperty("charset", mostCommon(charsets))
+	addProperty("insert_final_newline", mostCommon(finalNewlines))
+	if len(lineLengths) > 0 {
+		addProperty("max_line_length", strconv.Itoa(percentile(lineLengths, maxLineLengthPercentile)))
+	}
+
+	return section
+}
+
+// Infer measures the given files and proposes an .editorconfig section per extension,
+// counting how many files already conform to their proposed section
+func Infer(filePaths []string, config config.Config, maxLineLengthPercentile float64) (Proposal, error) {
+	groups := make(map[string][]string)
+	stats := make(map[string][]FileStats)
+
+	for _, filePath := range filePaths {
+		rawFileContent, err := files.ReadFile(filePath, config, nil)
+		if err != nil {
+			return Proposal{}, fmt.Errorf("reading %s: %w", filePath, err)
+		}
+
+		glob := SectionGlob(filePath)
+		groups[glob] = append(groups[glob], filePath)
+		stats[glob] = append(stats[glob], Measure(rawFileContent))
+	}
+
+	globs := make([]string, 0, len(groups))
+	for glob := range groups {
+		globs = append(globs, glob)
+	}
+	sort.Strings(globs)
+
+	var proposal Proposal
+	for _, glob := range globs {
+		section := propose(glob, stats[glob], maxLineLengthPercentile)
+
+		def := section.Definition()
+		for _, filePath := range groups[glob] {
+			if len(validation.ValidateFileWithDefinition(filePath, config, def)) == 0 {
+				section.ConformingCount++
+			} else {
+				config.Logger.Verbose("%s does not conform to the proposed [%s] section", filePath, glob)
+			}
+		}
+
+		proposal.Sections = append(proposal.Sections, section)
+	}
+
+	return proposal, nil
+}
@@ -0,0 +1,140 @@
+package infer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	// x-release-please-end
+)
+
+func TestSectionGlob(t *testing.T) {
+	sectionGlobTests := []struct {
+		filePath string
+		expected string
+	}{
+		{"main.go", "*.go"},
+		{"pkg/config/config.go", "*.go"},
+		{"archive.tar.gz", "*.gz"},
+		{"Makefile", "Makefile"},
+		{"docs/Makefile", "Makefile"},
+		{".gitignore", ".gitignore"},
+	}
+
+	for _, tt := range sectionGlobTests {
+		actual := SectionGlob(tt.filePath)
+		if actual != tt.expected {
+			t.Errorf("SectionGlob(%q): expected: %v, got: %v", tt.filePath, tt.expected, actual)
+		}
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	measureTests := []struct {
+		content  string
+		expected FileStats
+	}{
+		{"", FileStats{}},
+		{
+			"a\n\tb\n\t\tc\n",
+			FileStats{IndentStyle: "tab", EndOfLine: "lf", FinalNewline: "true", Charset: "utf-8", MaxLineLength: 3, HasLineContents: true},
+		},
+		{
+			"a\r\n    b\r\n        c\r\n    d",
+			FileStats{IndentStyle: "space", IndentSize: 4, EndOfLine: "crlf", FinalNewline: "false", Charset: "utf-8", MaxLineLength: 9, HasLineContents: true},
+		},
+		{
+			"/*\n * comment\n */\na\n  b\n    c\n  d\n",
+			FileStats{IndentStyle: "space", IndentSize: 2, EndOfLine: "lf", FinalNewline: "true", Charset: "utf-8", MaxLineLength: 10, HasLineContents: true},
+		},
+		{
+			"äöü\n",
+			FileStats{EndOfLine: "lf", FinalNewline: "true", Charset: "utf-8", MaxLineLength: 3, HasLineContents: true},
+		},
+	}
+
+	for _, tt := range measureTests {
+		actual := Measure([]byte(tt.content))
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("Measure(%q): expected: %+v, got: %+v", tt.content, tt.expected, actual)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []int{50, 10, 40, 20, 30, 60, 70, 80, 90, 100}
+	percentileTests := []struct {
+		percentile float64
+		expected   int
+	}{
+		{100, 100},
+		{95, 100},
+		{90, 90},
+		{50, 50},
+		{1, 10},
+	}
+
+	for _, tt := range percentileTests {
+		actual := percentile(values, tt.percentile)
+		if actual != tt.expected {
+			t.Errorf("percentile(%v, %v): expected: %v, got: %v", values, tt.percentile, tt.expected, actual)
+		}
+	}
+
+	if actual := percentile(nil, 95); actual != 0 {
+		t.Errorf("percentile(nil, 95): expected: 0, got: %v", actual)
+	}
+}
+
+func TestInfer(t *testing.T) {
+	dir := t.TempDir()
+	testFiles := map[string]string{
+		"a.go":     "package a\n\nfunc a() {\n\treturn\n}\n",
+		"b.go":     "package b\n\nfunc b() {\n\treturn\n}\n",
+		"c.go":     "package c\n\nfunc c() {\n    return\n}\n",
+		"d.yaml":   "a:\n  b:\n    c: d\n",
+		"Makefile": "all:\n\techo all\n",
+	}
+	var filePaths []string
+	for name, content := range testFiles {
+		filePath := filepath.Join(dir, name)
+		if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		filePaths = append(filePaths, filePath)
+	}
+
+	proposal, err := Infer(filePaths, *config.NewConfig(nil), DefaultMaxLineLengthPercentile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	globs := []string{}
+	for _, section := range proposal.Sections {
+		globs = append(globs, section.Glob)
+	}
+	if expected := []string{"*.go", "*.yaml", "Makefile"}; !reflect.DeepEqual(globs, expected) {
+		t.Errorf("expected sections %v, got %v", expected, globs)
+	}
+
+	goSection := proposal.Sections[0]
+	if goSection.FileCount != 3 || goSection.ConformingCount != 2 {
+		t.Errorf("expected 2 of 3 go files to conform, got %d of %d", goSection.ConformingCount, goSection.FileCount)
+	}
+	if def := goSection.Definition(); def.Raw["indent_style"] != "tab" {
+		t.Errorf("expected go files to be indented with tabs, got %q", def.Raw["indent_style"])
+	}
+
+	if proposal.FileCount() != 5 || proposal.ConformingCount() != 4 {
+		t.Errorf("expected 4 of 5 files to conform, got %d of %d", proposal.ConformingCount(), proposal.FileCount())
+	}
+
+	expected := "[*.yaml]\nindent_style = space\nindent_size = 2\nend_of_line = lf\ncharset = utf-8\ninsert_final_newline = true\nmax_line_length = 8\n"
+	if !strings.Contains(proposal.String(), expected) {
+		t.Errorf("expected the proposal to contain\n%s\ngot\n%s", expected, proposal.String())
+	}
+}
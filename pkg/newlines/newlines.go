@@ -0,0 +1,120 @@
+// Package newlines restricts validation errors to the lines which changed since a git ref
+package newlines
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	eccerror "github.com/editorconfig-checker/editorconfig-checker/v3/pkg/error"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/files"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/git"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/validation"
+	// x-release-please-end
+)
+
+// Filter knows which lines were added or modified since a ref
+type Filter struct {
+	ref        string
+	addedLines map[string][]git.LineRange
+	untracked  map[string]bool
+}
+
+// New collects the lines which were added or modified between the ref and the working tree
+func New(ref string) (Filter, error) {
+	addedLines, err := git.AddedLines(ref)
+	if err != nil {
+		return Filter{}, err
+	}
+
+	untrackedFiles, err := git.UntrackedFiles()
+	if err != nil {
+		return Filter{}, err
+	}
+	untracked := make(map[string]bool, len(untrackedFiles))
+	for _, untrackedFile := range untrackedFiles {
+		untracked[untrackedFile] = true
+	}
+
+	return Filter{ref: ref, addedLines: addedLines, untracked: untracked}, nil
+}
+
+// isNew returns whether the line of the file was added or modified
+func (f Filter) isNew(relativeFilePath string, lineNumber int) bool {
+	for _, lineRange := range f.addedLines[relativeFilePath] {
+		if lineRange.Contains(lineNumber) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply removes the errors on lines which were not added or modified since the ref.
+// Errors concerning the whole file, like a missing final newline, are only kept
+// if the file did not have them in the ref, so only regressions are reported.
+func (f Filter) Apply(errors []eccerror.ValidationErrors, config config.Config) []eccerror.ValidationErrors {
+	result := make([]eccerror.ValidationErrors, 0, len(errors))
+	for _, fileErrors := range errors {
+		relativeFilePath, err := files.GetRelativePath(fileErrors.FilePath)
+		if err != nil || f.untracked[relativeFilePath] {
+			// every line of an untracked file is new
+			result = append(result, fileErrors)
+			continue
+		}
+
+		var previousRules map[string]bool
+		var newErrors []eccerror.ValidationError
+		for _, singleError := range fileErrors.Errors {
+			if singleError.LineNumber == -1 {
+				if previousRules == nil {
+					previousRules = f.previousFileRules(fileErrors.FilePath, relativeFilePath, config)
+				}
+				if previousRules[singleError.Rule] {
+					config.Logger.Verbose("Not reporting %s of %s, it already occurred in %s", singleError.Rule, fileErrors.FilePath, f.ref)
+					continue
+				}
+			} else if !f.isNew(relativeFilePath, singleError.LineNumber) {
+				continue
+			}
+			newErrors = append(newErrors, singleError)
+		}
+
+		result = append(result, eccerror.ValidationErrors{FilePath: fileErrors.FilePath, Errors: newErrors})
+	}
+
+	return result
+}
+
+// previousFileRules returns the rules of the errors concerning the whole file which the file had in the ref,
+// validated with the current .editorconfig
+func (f Filter) previousFileRules(filePath string, relativeFilePath string, config config.Config) map[string]bool {
+	rules := make(map[string]bool)
+
+	previousContent, err := git.Show(f.ref, relativeFilePath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			config.Logger.Error("Could not read %s in %s: %s", relativeFilePath, f.ref, err.Error())
+		}
+		return rules
+	}
+
+	if config.EditorconfigConfig == nil {
+		config.EditorconfigConfig = &editorconfig.Config{}
+	}
+	def, _, err := config.EditorconfigConfig.LoadGraceful(filePath)
+	if err != nil {
+		config.Logger.Error("cannot load %s as .editorconfig: %s", filePath, err)
+		return rules
+	}
+
+	for _, previousError := range validation.ValidateContentWithDefinition(filePath, previousContent, config, def) {
+		if previousError.LineNumber == -1 {
+			rules[previousError.Rule] = true
+		}
+	}
+
+	return rules
+}
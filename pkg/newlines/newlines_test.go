@@ -0,0 +1,77 @@
+package newlines
+
+import (
+	"os"
+	"testing"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	eccerror "github.com/editorconfig-checker/editorconfig-checker/v3/pkg/error"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/git"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/validation"
+	// x-release-please-end
+)
+
+func writeFile(t *testing.T, name string, content string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func runGit(t *testing.T, args ...string) {
+	t.Helper()
+	if _, err := git.Run(args...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func rules(fileErrors eccerror.ValidationErrors) []string {
+	var rules []string
+	for _, singleError := range fileErrors.Errors {
+		rules = append(rules, singleError.Rule)
+	}
+	return rules
+}
+
+func TestApply(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFile(t, ".editorconfig", "root = true\n\n[*]\ntrim_trailing_whitespace = true\ninsert_final_newline = true\n")
+	writeFile(t, "legacy.txt", "old \nold \nold")
+	writeFile(t, "regressed.txt", "fine\n")
+	runGit(t, "init", "--quiet")
+	runGit(t, "add", "--all")
+	runGit(t, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--message", "initial")
+
+	// one line with trailing whitespace is added, the missing final newline is not new
+	writeFile(t, "legacy.txt", "old \nnew \nold \nold")
+	// the final newline is removed
+	writeFile(t, "regressed.txt", "fine")
+	writeFile(t, "untracked.txt", "untracked \n")
+
+	configuration := *config.NewConfig(nil)
+	errors := validation.ProcessValidation([]string{"legacy.txt", "regressed.txt", "untracked.txt"}, configuration)
+
+	filter, err := New("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	errors = filter.Apply(errors, configuration)
+
+	if len(errors) != 3 {
+		t.Fatalf("expected the errors of three files, got %+v", errors)
+	}
+
+	legacy := errors[0]
+	if len(legacy.Errors) != 1 || legacy.Errors[0].LineNumber != 2 || legacy.Errors[0].Rule != validation.RuleTrimTrailingWhitespace {
+		t.Errorf("expected only the trailing whitespace on the added line 2 of legacy.txt, got %+v", legacy.Errors)
+	}
+
+	if got := rules(errors[1]); len(got) != 1 || got[0] != validation.RuleInsertFinalNewline {
+		t.Errorf("expected the removed final newline of regressed.txt, got %v", got)
+	}
+
+	if got := rules(errors[2]); len(got) != 1 || got[0] != validation.RuleTrimTrailingWhitespace {
+		t.Errorf("expected every error of untracked.txt, got %v", got)
+	}
+}
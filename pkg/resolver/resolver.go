@@ -0,0 +1,105 @@
+// Package resolver parses .editorconfig files, so the definitions of many files can be resolved at once
+package resolver
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+)
+
+// Parser is an editorconfig.Parser which is safe for concurrent use,
+// so an editorconfig.Config using it resolves the definitions of files from many goroutines without a lock.
+// The .editorconfig file of every directory is read and parsed once, including the ones which do not exist.
+type Parser struct {
+	reader FileReader
+	// editorconfigs maps the file names to their *parsed .editorconfig
+	editorconfigs sync.Map
+	// fnmatchers holds *editorconfig.CachedParser, which compile the globs of the sections once,
+	// but are not safe for concurrent use, so every goroutine uses one of its own
+	fnmatchers sync.Pool
+}
+
+// FileReader reads the .editorconfig files.
+// It must be safe for concurrent use and return an error matching fs.ErrNotExist for a file which does not exist.
+type FileReader interface {
+	ReadFile(filename string) ([]byte, error)
+}
+
+// FileSystem is the FileReader of the file system
+type FileSystem struct{}
+
+// ReadFile reads a file from the file system
+func (FileSystem) ReadFile(filename string) ([]byte, error) {
+	return os.ReadFile(filename)
+}
+
+// parsed is the result of parsing an .editorconfig file
+type parsed struct {
+	once         sync.Once
+	editorconfig *editorconfig.Editorconfig
+	err          error
+}
+
+// NewParser creates a Parser which reads the .editorconfig files with the reader
+func NewParser(reader FileReader) *Parser {
+	return &Parser{reader: reader}
+}
+
+// IsConcurrencySafe returns whether the config resolves definitions with a Parser,
+// so its LoadGraceful can be called concurrently
+func IsConcurrencySafe(config *editorconfig.Config) bool {
+	_, ok := config.Parser.(*Parser)
+	return ok
+}
+
+// ParseIni parses the .editorconfig at the path
+func (p *Parser) ParseIni(filename string) (*editorconfig.Editorconfig, error) {
+	ec, warning, err := p.ParseIniGraceful(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ec, warning
+}
+
+// ParseIniGraceful parses the .editorconfig at the path and returns the warnings separately.
+// Like editorconfig.CachedParser, only the call which parses the file returns its warnings.
+func (p *Parser) ParseIniGraceful(filename string) (*editorconfig.Editorconfig, error, error) {
+	value, ok := p.editorconfigs.Load(filename)
+	if !ok {
+		value, _ = p.editorconfigs.LoadOrStore(filename, &parsed{})
+	}
+	result := value.(*parsed)
+
+	var warning error
+	result.once.Do(func() {
+		content, err := p.reader.ReadFile(filename)
+		if err != nil {
+			result.err = err
+			return
+		}
+		result.editorconfig, warning, err = editorconfig.ParseGraceful(bytes.NewReader(content))
+		if err != nil {
+			result.err = fmt.Errorf("error loading ini file %q: %w", filename, err)
+		}
+	})
+	if result.err != nil {
+		return nil, nil, result.err
+	}
+
+	// LoadGraceful sets its config on the editorconfig it gets, so every call gets a copy of its own
+	ec := *result.editorconfig
+	return &ec, warning, nil
+}
+
+// FnmatchCase returns whether the filename matches the glob of a section
+func (p *Parser) FnmatchCase(pattern string, filename string) (bool, error) {
+	fnmatcher, ok := p.fnmatchers.Get().(*editorconfig.CachedParser)
+	if !ok {
+		fnmatcher = editorconfig.NewCachedParser()
+	}
+	defer p.fnmatchers.Put(fnmatcher)
+	return fnmatcher.FnmatchCase(pattern, filename)
+}
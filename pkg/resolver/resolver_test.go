@@ -0,0 +1,147 @@
+package resolver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+)
+
+// countingReader counts how often every file is read
+type countingReader struct {
+	lock  sync.Mutex
+	reads map[string]int
+}
+
+func (r *countingReader) ReadFile(filename string) ([]byte, error) {
+	r.lock.Lock()
+	r.reads[filename]++
+	r.lock.Unlock()
+	return os.ReadFile(filename)
+}
+
+// writeTree writes .editorconfig files to a directory and returns the paths of the files to resolve
+func writeTree(t testing.TB) []string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		".editorconfig":     "root = true\n\n[*]\nindent_style = space\nindent_size = 4\n\n[*.go]\nindent_style = tab\n",
+		"sub/.editorconfig": "[*.md]\nmax_line_length = 80\n\n[{Makefile,*.mk}]\nindent_style = tab\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var filePaths []string
+	for _, subDir := range []string{"", "sub", "sub/deeper"} {
+		for _, name := range []string{"main.go", "README.md", "Makefile", "rules.mk", "file.txt"} {
+			filePaths = append(filePaths, filepath.Join(dir, subDir, name))
+		}
+	}
+	return filePaths
+}
+
+func TestParser(t *testing.T) {
+	filePaths := writeTree(t)
+	reader := &countingReader{reads: make(map[string]int)}
+	config := &editorconfig.Config{Parser: NewParser(reader)}
+	if !IsConcurrencySafe(config) {
+		t.Error("expected a config with a Parser to be safe for concurrent use")
+	}
+
+	expected := make([]*editorconfig.Definition, len(filePaths))
+	for i, filePath := range filePaths {
+		definition, _, err := (&editorconfig.Config{Parser: editorconfig.NewCachedParser()}).LoadGraceful(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected[i] = definition
+	}
+
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, filePath := range filePaths {
+				definition, _, err := config.LoadGraceful(filePath)
+				if err != nil {
+					t.Errorf("LoadGraceful(%s): expected nil, got %v", filePath, err)
+					continue
+				}
+				if !reflect.DeepEqual(definition, expected[i]) {
+					t.Errorf("LoadGraceful(%s): expected %+v, got %+v", filePath, expected[i], definition)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the .editorconfig of every directory is read once, also the one of sub/deeper, which does not exist
+	if len(reader.reads) != 3 {
+		t.Errorf("expected the .editorconfig files of 3 directories to be read, got %v", reader.reads)
+	}
+	for filename, reads := range reader.reads {
+		if reads != 1 {
+			t.Errorf("expected %s to be read once, got %d", filename, reads)
+		}
+	}
+
+	if IsConcurrencySafe(&editorconfig.Config{}) {
+		t.Error("expected a config without a Parser not to be safe for concurrent use")
+	}
+}
+
+func TestParserInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".editorconfig"), []byte("[*\nroot"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := &editorconfig.Config{Parser: NewParser(FileSystem{})}
+	for range 2 {
+		if _, _, err := config.LoadGraceful(filepath.Join(dir, "file.txt")); err == nil {
+			t.Error("expected the invalid .editorconfig to be an error, got nil")
+		}
+	}
+}
+
+// BenchmarkLoadGraceful compares resolving definitions behind one lock, like it was done before,
+// with resolving them concurrently, run it with -cpu 1,2,4,8 to see how they scale
+func BenchmarkLoadGraceful(b *testing.B) {
+	filePaths := writeTree(b)
+
+	b.Run("locked", func(b *testing.B) {
+		var lock sync.Mutex
+		config := &editorconfig.Config{Parser: editorconfig.NewCachedParser()}
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				lock.Lock()
+				_, _, err := config.LoadGraceful(filePaths[i%len(filePaths)])
+				lock.Unlock()
+				if err != nil {
+					panic(fmt.Sprint(err))
+				}
+			}
+		})
+	})
+
+	b.Run("resolver", func(b *testing.B) {
+		config := &editorconfig.Config{Parser: NewParser(FileSystem{})}
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				if _, _, err := config.LoadGraceful(filePaths[i%len(filePaths)]); err != nil {
+					panic(fmt.Sprint(err))
+				}
+			}
+		})
+	})
+}
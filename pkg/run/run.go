@@ -0,0 +1,123 @@
+// Package run holds the state of a run besides the settings of its config,
+// which is shared by the discovery and the validation of the files
+package run
+
+import (
+	"os"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/archive"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/cache"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/events"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/gitignore"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/source"
+	// x-release-please-end
+)
+
+// State is the state of a run.
+// A nil State checks the files of the file system without a cache and events,
+// and reads the archives and the .ecignore files every time they are needed.
+type State struct {
+	// Source provides the files to check instead of the working tree, if set
+	Source source.Source
+	// Cache holds the errors of the files which did not change since a previous run, if set
+	Cache *cache.Cache
+	// Events receives the progress of the run, if set
+	Events events.Subscriber
+	// Archives holds the archives read during the run
+	Archives *archive.Cache
+
+	ignoreFiles *gitignore.DirectoryMatcher
+}
+
+// New creates the State of a run, which checks the files of the file system until a Source is set
+func New() *State {
+	s := &State{Archives: archive.NewCache()}
+	s.ignoreFiles = gitignore.NewDirectoryMatcher(config.IgnoreFileName, s.ReadFile)
+	return s
+}
+
+// Notify sends the event to the Events subscriber, if set
+func (s *State) Notify(event events.Event) {
+	if s != nil && s.Events != nil {
+		s.Events.Notify(event)
+	}
+}
+
+// IsInMemory returns whether a file is inside of an archive or from the Source,
+// whose content is read into memory as a whole
+func (s *State) IsInMemory(filePath string) bool {
+	return archive.IsEntry(filePath) || s.source() != nil
+}
+
+// ReadFile returns the content of a file inside of an archive, from the Source,
+// or the file system if none is set
+func (s *State) ReadFile(filePath string) ([]byte, error) {
+	if archive.IsEntry(filePath) {
+		return s.archives().ReadFile(filePath)
+	}
+	if s.source() != nil {
+		return s.Source.ReadFile(filePath)
+	}
+	return os.ReadFile(filePath)
+}
+
+// ReadFileWithSize returns the content of a file like ReadFile along with the size of the whole file,
+// as only the first MaxFileSize bytes of the files inside of archives are held in memory
+func (s *State) ReadFileWithSize(filePath string) ([]byte, int64, error) {
+	if archive.IsEntry(filePath) {
+		return s.archives().ReadFileWithSize(filePath)
+	}
+	content, err := s.ReadFile(filePath)
+	return content, int64(len(content)), err
+}
+
+// ListArchive returns the paths of the files inside of the archive, joined to the path of the archive
+func (s *State) ListArchive(archivePath string) ([]string, error) {
+	return s.archives().List(archivePath)
+}
+
+// IgnoreFiles returns the matcher of the .ecignore files, which reads them as they are needed.
+// It is not safe for concurrent use.
+func (s *State) IgnoreFiles() *gitignore.DirectoryMatcher {
+	if s == nil {
+		return gitignore.NewDirectoryMatcher(config.IgnoreFileName, s.ReadFile)
+	}
+	return s.ignoreFiles
+}
+
+// ReloadIgnoreFiles discards the .ecignore files read, so their changes are read
+func (s *State) ReloadIgnoreFiles() {
+	s.ignoreFiles = gitignore.NewDirectoryMatcher(config.IgnoreFileName, s.ReadFile)
+}
+
+// ReloadEditorconfigs discards the archives read and the .editorconfig files parsed by the config,
+// which reads the .editorconfig files inside of archives from the archives of the State afterwards.
+// The files inside of the archives read afterwards are held in memory up to the MaxFileSize of the config,
+// as larger ones are skipped or only their first lines are checked.
+func (s *State) ReloadEditorconfigs(cfg *config.Config) {
+	s.Archives = archive.NewCache()
+	s.Archives.MaxEntrySize = cfg.MaxFileSize
+	cfg.EditorconfigConfig = &editorconfig.Config{
+		Parser: archive.NewParser(s.Archives),
+	}
+}
+
+// source returns the Source, which is nil for a nil State
+func (s *State) source() source.Source {
+	if s == nil {
+		return nil
+	}
+	return s.Source
+}
+
+// archives returns the Archives, which are nil for a nil State, so they are read every time
+func (s *State) archives() *archive.Cache {
+	if s == nil {
+		return nil
+	}
+	return s.Archives
+}
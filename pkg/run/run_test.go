@@ -0,0 +1,71 @@
+package run
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/events"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/source"
+	// x-release-please-end
+)
+
+func TestNilState(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("file.txt", []byte("content\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(config.IgnoreFileName, []byte("*.log\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// a nil State reads the files of the file system and drops the events
+	var state *State
+	state.Notify(events.Event{Kind: events.FileDiscovered, FilePath: "file.txt"})
+	if state.IsInMemory("file.txt") {
+		t.Error("IsInMemory: expected a file of the file system not to be held in memory")
+	}
+	if content, err := state.ReadFile("file.txt"); err != nil || string(content) != "content\n" {
+		t.Errorf("ReadFile: expected %q, got %q, %v", "content\n", content, err)
+	}
+	if excluded, matched := state.IgnoreFiles().Lookup("debug.log", false); !excluded || !matched {
+		t.Errorf("IgnoreFiles: expected debug.log to be excluded by the .ecignore, got %v, %v", excluded, matched)
+	}
+}
+
+func TestState(t *testing.T) {
+	var received []events.Event
+	state := New()
+	state.Events = events.SubscriberFunc(func(event events.Event) {
+		received = append(received, event)
+	})
+	state.Source = source.NewBuffer("file.txt", []byte("from the source\n"))
+
+	state.Notify(events.Event{Kind: events.FileDiscovered, FilePath: "file.txt"})
+	if len(received) != 1 {
+		t.Errorf("Notify: expected the event to be received, got %v", received)
+	}
+
+	// the files of the source are read instead of the ones of the file system
+	if !state.IsInMemory("file.txt") {
+		t.Error("IsInMemory: expected a file of the source to be held in memory")
+	}
+	if content, err := state.ReadFile("file.txt"); err != nil || string(content) != "from the source\n" {
+		t.Errorf("ReadFile: expected %q, got %q, %v", "from the source\n", content, err)
+	}
+	if _, err := state.ReadFile(filepath.Join("missing", "file.txt")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFile: expected a missing file of the source not to exist, got %v", err)
+	}
+
+	// the editorconfigs of the config are read from the archives of the state
+	cfg := config.NewConfig(nil)
+	previous := state.Archives
+	state.ReloadEditorconfigs(cfg)
+	if state.Archives == previous || cfg.EditorconfigConfig == nil {
+		t.Error("ReloadEditorconfigs: expected new archives and editorconfigs")
+	}
+}
@@ -0,0 +1,231 @@
+// Package source contains the places files can be checked from other than the working tree
+package source
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/git"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/resolver"
+	// x-release-please-end
+)
+
+// Source provides the files to check and their content
+// The paths are relative to the current working directory and use slashes.
+// A Source must be safe for concurrent use.
+type Source interface {
+	// ListFiles returns the paths of all files of the source
+	ListFiles() ([]string, error)
+	// ReadFile returns the content of a file of the source
+	ReadFile(filePath string) ([]byte, error)
+}
+
+// Index is the content staged in the git index, which is what is about to be committed
+type Index struct {
+	// prefix is the path of the current working directory relative to the root of the repository
+	prefix  string
+	catFile *git.CatFile
+}
+
+// NewIndex creates an Index of the repository of the current working directory.
+// It must be closed after use.
+func NewIndex() (*Index, error) {
+	prefix, err := git.Prefix()
+	if err != nil {
+		return nil, err
+	}
+
+	catFile, err := git.NewCatFile()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Index{prefix: prefix, catFile: catFile}, nil
+}
+
+// ListFiles returns the paths of the files which were added or modified in the index
+func (i *Index) ListFiles() ([]string, error) {
+	return git.StagedFiles()
+}
+
+// ReadFile returns the staged content of a file.
+// Files outside of the current working directory can be read with a relative path like ../.editorconfig
+func (i *Index) ReadFile(filePath string) ([]byte, error) {
+	indexPath := path.Join(i.prefix, filepath.ToSlash(filePath))
+	if indexPath == ".." || strings.HasPrefix(indexPath, "../") {
+		return nil, fmt.Errorf("%s is outside of the repository: %w", filePath, fs.ErrNotExist)
+	}
+	// :<path> is the object of the path in the index
+	content, err := i.catFile.Read(":" + indexPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s does not exist in the index: %w", filePath, fs.ErrNotExist)
+	}
+	return content, err
+}
+
+// Close stops reading the content of the index
+func (i *Index) Close() error {
+	return i.catFile.Close()
+}
+
+// Buffer is a single file which content is held in memory, like an unsaved buffer of an editor read from stdin.
+// Other files, like .ecignore files, are read from the file system.
+type Buffer struct {
+	filePath string
+	content  []byte
+}
+
+// NewBuffer creates a Buffer of the content as if it was the file at the path
+func NewBuffer(filePath string, content []byte) Buffer {
+	return Buffer{filePath: filePath, content: content}
+}
+
+// ListFiles returns the path of the buffer
+func (b Buffer) ListFiles() ([]string, error) {
+	return []string{b.filePath}, nil
+}
+
+// ReadFile returns the content of the buffer for its path, and the content of the file system for any other one
+func (b Buffer) ReadFile(filePath string) ([]byte, error) {
+	if filepath.Clean(filePath) == filepath.Clean(b.filePath) {
+		return b.content, nil
+	}
+	return os.ReadFile(filePath)
+}
+
+// FS is the content of an fs.FS, like an fstest.MapFS, whose root is the current working directory
+type FS struct {
+	fsys fs.FS
+}
+
+// NewFS creates an FS of the files of the fs.FS
+func NewFS(fsys fs.FS) FS {
+	return FS{fsys: fsys}
+}
+
+// ListFiles returns the paths of all regular files of the fs.FS
+func (f FS) ListFiles() ([]string, error) {
+	var filePaths []string
+	err := fs.WalkDir(f.fsys, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			filePaths = append(filePaths, filePath)
+		}
+		return nil
+	})
+	return filePaths, err
+}
+
+// ReadFile returns the content of a file of the fs.FS.
+// Files outside of its root, like ../.editorconfig, do not exist.
+func (f FS) ReadFile(filePath string) ([]byte, error) {
+	name := path.Clean(filepath.ToSlash(filePath))
+	if !fs.ValidPath(name) {
+		return nil, fmt.Errorf("%s is outside of the file system: %w", filePath, fs.ErrNotExist)
+	}
+	return fs.ReadFile(f.fsys, name)
+}
+
+// Tree is the content of a git tree, like the one of a commit, without checking it out
+type Tree struct {
+	ref string
+	// prefix is the path of the current working directory relative to the root of the repository
+	prefix string
+	// objects are the blobs of the tree by their path relative to the root of the repository
+	objects map[string]string
+	catFile *git.CatFile
+}
+
+// NewTree lists the files of the tree of a ref, like a commit, branch or tag.
+// It must be closed after use.
+func NewTree(ref string) (*Tree, error) {
+	prefix, err := git.Prefix()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := git.ListTree(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		// submodules are commits and symbolic links have no content to check
+		if entry.Type == "blob" && entry.Mode != "120000" {
+			objects[entry.Path] = entry.Object
+		}
+	}
+
+	catFile, err := git.NewCatFile()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tree{ref: ref, prefix: prefix, objects: objects, catFile: catFile}, nil
+}
+
+// ListFiles returns the paths of the files of the tree within the current working directory
+func (t *Tree) ListFiles() ([]string, error) {
+	filePaths := make([]string, 0, len(t.objects))
+	for treePath := range t.objects {
+		if strings.HasPrefix(treePath, t.prefix) {
+			filePaths = append(filePaths, strings.TrimPrefix(treePath, t.prefix))
+		}
+	}
+	sort.Strings(filePaths)
+	return filePaths, nil
+}
+
+// ReadFile returns the content of a file of the tree.
+// Files outside of the current working directory can be read with a relative path like ../.editorconfig
+func (t *Tree) ReadFile(filePath string) ([]byte, error) {
+	treePath := path.Join(t.prefix, filepath.ToSlash(filePath))
+	object, ok := t.objects[treePath]
+	if !ok {
+		return nil, fmt.Errorf("%s does not exist in %s: %w", filePath, t.ref, fs.ErrNotExist)
+	}
+	return t.catFile.Read(object)
+}
+
+// Close stops reading the content of the tree
+func (t *Tree) Close() error {
+	return t.catFile.Close()
+}
+
+// NewParser creates a parser which reads the .editorconfig files from a source,
+// so their content is the one of the source rather than the one of the working tree
+func NewParser(source Source) *resolver.Parser {
+	return resolver.NewParser(fileReader{source})
+}
+
+// fileReader reads the .editorconfig files from a source
+type fileReader struct {
+	source Source
+}
+
+// ReadFile reads a file of the source.
+// The path is usually absolute, it is read relative to the current working directory from the source.
+func (r fileReader) ReadFile(filename string) ([]byte, error) {
+	relativeFilename := filename
+	if filepath.IsAbs(filename) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		relativeFilename, err = filepath.Rel(cwd, filename)
+		if err != nil {
+			return nil, fmt.Errorf("%s is outside of the source: %w", filename, fs.ErrNotExist)
+		}
+	}
+	return r.source.ReadFile(relativeFilename)
+}
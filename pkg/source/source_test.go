@@ -0,0 +1,202 @@
+package source
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/git"
+	// x-release-please-end
+)
+
+func runGit(t *testing.T, args ...string) {
+	t.Helper()
+	if _, err := git.Run(args...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeFile(t *testing.T, name string, content string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	t.Chdir(t.TempDir())
+	runGit(t, "init", "--quiet")
+	writeFile(t, "committed.txt", "committed\n")
+	runGit(t, "add", "--all")
+	runGit(t, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--message", "initial")
+
+	writeFile(t, "staged.txt", "staged\n")
+	runGit(t, "add", "staged.txt")
+	writeFile(t, "staged.txt", "modified after staging\n")
+	writeFile(t, "unstaged.txt", "unstaged\n")
+
+	index, err := NewIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer index.Close()
+
+	filePaths, err := index.ListFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"staged.txt"}; !reflect.DeepEqual(filePaths, expected) {
+		t.Errorf("expected %v, got %v", expected, filePaths)
+	}
+
+	content, err := index.ReadFile("staged.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "staged\n" {
+		t.Errorf("expected the staged content, got %q", content)
+	}
+
+	for _, filePath := range []string{"unstaged.txt", "../outside.txt"} {
+		if _, err := index.ReadFile(filePath); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("ReadFile(%s): expected fs.ErrNotExist, got %v", filePath, err)
+		}
+	}
+
+	// the files of the whole repository are read from a sub directory
+	if err := os.Mkdir("sub", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir("sub")
+	subIndex, err := NewIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer subIndex.Close()
+	if content, err := subIndex.ReadFile("../committed.txt"); err != nil || string(content) != "committed\n" {
+		t.Errorf("ReadFile(../committed.txt): expected %q, got %q, %v", "committed\n", content, err)
+	}
+}
+
+func TestTree(t *testing.T) {
+	t.Chdir(t.TempDir())
+	runGit(t, "init", "--quiet")
+	if err := os.Mkdir("sub", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, ".editorconfig", "root = true\n\n[*.txt]\nindent_style = tab\n")
+	writeFile(t, "sub/a.txt", "committed\n")
+	runGit(t, "add", "--all")
+	runGit(t, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--message", "initial")
+	writeFile(t, ".editorconfig", "root = true\n\n[*.txt]\nindent_style = space\n")
+	writeFile(t, "sub/a.txt", "modified\n")
+	writeFile(t, "sub/untracked.txt", "untracked\n")
+	t.Chdir("sub")
+
+	tree, err := NewTree("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tree.Close()
+
+	filePaths, err := tree.ListFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"a.txt"}; !reflect.DeepEqual(filePaths, expected) {
+		t.Errorf("expected %v, got %v", expected, filePaths)
+	}
+
+	content, err := tree.ReadFile("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "committed\n" {
+		t.Errorf("expected the committed content, got %q", content)
+	}
+
+	if _, err := tree.ReadFile("untracked.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist for a file which is not in the tree, got %v", err)
+	}
+
+	// the properties are the ones of the committed .editorconfig in the parent directory
+	editorconfigConfig := editorconfig.Config{Parser: NewParser(tree)}
+	def, _, err := editorconfigConfig.LoadGraceful("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.IndentStyle != "tab" {
+		t.Errorf("expected the indent_style of the tree, got %q", def.IndentStyle)
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFile(t, "on-disk.txt", "on disk\n")
+	writeFile(t, "unsaved.txt", "saved\n")
+
+	buffer := NewBuffer("./unsaved.txt", []byte("unsaved\n"))
+
+	filePaths, err := buffer.ListFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"./unsaved.txt"}; !reflect.DeepEqual(filePaths, expected) {
+		t.Errorf("expected %v, got %v", expected, filePaths)
+	}
+
+	for filePath, expected := range map[string]string{"unsaved.txt": "unsaved\n", "on-disk.txt": "on disk\n"} {
+		content, err := buffer.ReadFile(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != expected {
+			t.Errorf("ReadFile(%s): expected %q, got %q", filePath, expected, content)
+		}
+	}
+}
+
+func TestFS(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFile(t, ".editorconfig", "root = true\n\n[*.txt]\nindent_style = space\n")
+	fsys := NewFS(fstest.MapFS{
+		".editorconfig": {Data: []byte("root = true\n\n[*.txt]\nindent_style = tab\n")},
+		"sub/a.txt":     {Data: []byte("in memory\n")},
+	})
+
+	filePaths, err := fsys.ListFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{".editorconfig", "sub/a.txt"}; !reflect.DeepEqual(filePaths, expected) {
+		t.Errorf("expected %v, got %v", expected, filePaths)
+	}
+
+	content, err := fsys.ReadFile("./sub/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "in memory\n" {
+		t.Errorf("expected the content of the file system, got %q", content)
+	}
+
+	if _, err := fsys.ReadFile("../.editorconfig"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist for a file outside of the file system, got %v", err)
+	}
+
+	// the properties are the ones of the .editorconfig of the file system, not the one on disk
+	editorconfigConfig := editorconfig.Config{Parser: NewParser(fsys)}
+	def, _, err := editorconfigConfig.LoadGraceful("sub/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.IndentStyle != "tab" {
+		t.Errorf("expected the indent_style of the file system, got %q", def.IndentStyle)
+	}
+}
@@ -0,0 +1,222 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/encoding"
+	eccerror "github.com/editorconfig-checker/editorconfig-checker/v3/pkg/error"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/events"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/files"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/resolver"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/run"
+	// x-release-please-end
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+)
+
+// ProcessValidation Validates all files and returns an array of validation errors
+func ProcessValidation(filePaths []string, config config.Config) []eccerror.ValidationErrors {
+	validationErrors, _ := ProcessValidationContext(context.Background(), filePaths, config, nil)
+	return validationErrors
+}
+
+// ProcessValidationContext Validates all files like ProcessValidation until the context is done.
+// Files whose validation has not started when the context is done are not validated,
+// and the error of the context is returned.
+func ProcessValidationContext(ctx context.Context, filePaths []string, config config.Config, state *run.State) ([]eccerror.ValidationErrors, error) {
+	validationErrors, _, err := collectValidation(ctx, pathsToFiles(filePaths), config, state)
+	return validationErrors, err
+}
+
+// FileError is why a file could not be validated completely,
+// like an .editorconfig which cannot be loaded or content which cannot be read or decoded
+type FileError struct {
+	FilePath string
+	Err      error
+}
+
+// Error returns the message of the error, which names the file
+func (e FileError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the file
+func (e FileError) Unwrap() error {
+	return e.Err
+}
+
+// ProcessDiscoveredValidation validates the files returned by files.DiscoverAll like ProcessValidationContext,
+// but with the ContentType detected while discovering them, so it is not detected again.
+// The files which could not be validated completely are returned as well, in no particular order.
+func ProcessDiscoveredValidation(ctx context.Context, discovered []files.File, config config.Config, state *run.State) ([]eccerror.ValidationErrors, []FileError, error) {
+	return collectValidation(ctx, discovered, config, state)
+}
+
+// collectValidation validates the files, whose Index must be their position, and returns their errors in that order
+func collectValidation(ctx context.Context, discovered []files.File, config config.Config, state *run.State) ([]eccerror.ValidationErrors, []FileError, error) {
+	validationErrors := make([]*eccerror.ValidationErrors, len(discovered))
+	var fileErrors []FileError
+	err := processValidation(ctx, sendFiles(ctx, discovered), config, state, func(file files.File, errors *eccerror.ValidationErrors, err error) {
+		validationErrors[file.Index] = errors
+		if err != nil {
+			fileErrors = append(fileErrors, FileError{FilePath: file.Path, Err: err})
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Remove all nil values
+	result := make([]eccerror.ValidationErrors, 0, len(validationErrors))
+	for _, validationError := range validationErrors {
+		if validationError != nil {
+			result = append(result, *validationError)
+		}
+	}
+
+	return result, fileErrors, nil
+}
+
+// StreamValidation validates all files like ProcessValidationContext, but rather than returning the errors,
+// it passes the errors of every file to handle as soon as the file is validated,
+// so they can be printed right away and need not be held until all files are validated.
+// handle is called with the index of the file in filePaths, for one file at a time in the order the files are validated in,
+// and without errors for a file whose .editorconfig cannot be loaded.
+func StreamValidation(ctx context.Context, filePaths []string, config config.Config, state *run.State, handle func(index int, fileErrors eccerror.ValidationErrors)) error {
+	return StreamDiscoveredValidation(ctx, sendFiles(ctx, pathsToFiles(filePaths)), config, state, handle)
+}
+
+// StreamDiscoveredValidation validates the files sent by files.Discover like StreamValidation,
+// so the files are validated while others are still being discovered.
+// handle is called with the Index of every file, without errors for the skipped ones.
+func StreamDiscoveredValidation(ctx context.Context, found <-chan files.File, config config.Config, state *run.State, handle func(index int, fileErrors eccerror.ValidationErrors)) error {
+	return processValidation(ctx, found, config, state, func(file files.File, fileErrors *eccerror.ValidationErrors, _ error) {
+		if fileErrors == nil {
+			handle(file.Index, eccerror.ValidationErrors{FilePath: file.Path})
+			return
+		}
+		handle(file.Index, *fileErrors)
+	})
+}
+
+// pathsToFiles returns the files of the paths, numbered in their order
+func pathsToFiles(filePaths []string) []files.File {
+	discovered := make([]files.File, 0, len(filePaths))
+	for i, filePath := range filePaths {
+		discovered = append(discovered, files.File{Index: i, Path: filePath})
+	}
+	return discovered
+}
+
+// sendFiles sends the files to the returned channel in order, until the context is done
+func sendFiles(ctx context.Context, discovered []files.File) <-chan files.File {
+	found := make(chan files.File)
+	go func() {
+		defer close(found)
+		for _, file := range discovered {
+			select {
+			case found <- file:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return found
+}
+
+// processValidation validates the files received with as many workers as the config has
+// and calls handle for one file at a time once it is validated,
+// with nil errors if it is skipped, its .editorconfig cannot be loaded or it cannot be read,
+// and with the error if it could not be validated completely
+func processValidation(ctx context.Context, found <-chan files.File, config config.Config, state *run.State, handle func(file files.File, fileErrors *eccerror.ValidationErrors, err error)) error {
+	if config.EditorconfigConfig == nil {
+		config.EditorconfigConfig = &editorconfig.Config{Parser: resolver.NewParser(resolver.FileSystem{})}
+	}
+	// an EditorconfigConfig with another parser isn't thread safe, so we need to acquire a lock
+	concurrencySafe := resolver.IsConcurrencySafe(config.EditorconfigConfig)
+	// compile the custom rules once, so they are shared by the copies of the config
+	if _, err := config.CachedCustomRules(); err != nil {
+		// receive the files anyway, so the sender is not blocked forever
+		for range found {
+		}
+		return err
+	}
+
+	var (
+		wg         sync.WaitGroup
+		lock       sync.Mutex
+		handleLock sync.Mutex
+	)
+	handleLocked := func(file files.File, fileErrors *eccerror.ValidationErrors, err error) {
+		handleLock.Lock()
+		defer handleLock.Unlock()
+		handle(file, fileErrors, err)
+	}
+
+	for range config.Workers() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range found {
+				// the remaining files are received without being validated
+				if ctx.Err() != nil {
+					continue
+				}
+				if file.Skipped {
+					handleLocked(file, nil, nil)
+					continue
+				}
+
+				filePath := file.Path
+				config.Logger.Verbose("Validate %s", filePath)
+				state.Notify(events.Event{Kind: events.FileStarted, FilePath: filePath})
+
+				if !concurrencySafe {
+					lock.Lock()
+				}
+				def, warnings, err := config.EditorconfigConfig.LoadGraceful(filePath)
+				if !concurrencySafe {
+					lock.Unlock()
+				}
+				if err != nil {
+					err = fmt.Errorf("cannot load %s as .editorconfig: %w", filePath, err)
+					config.Logger.Error("%v", err.Error())
+					state.Notify(events.Event{Kind: events.FileSkipped, FilePath: filePath, Reason: "its .editorconfig cannot be loaded"})
+					handleLocked(file, nil, err)
+					continue
+				}
+				if warnings != nil {
+					config.Logger.Warning("%v", warnings.Error())
+				}
+				validationErrors, err := validateFile(file, config, state, def)
+				if err != nil {
+					config.Logger.Error("%v", err.Error())
+				}
+				if validationErrors == nil && err != nil && !isDecodeError(err) {
+					// the file cannot be read, like one which was deleted since it was found
+					state.Notify(events.Event{Kind: events.FileSkipped, FilePath: filePath, Reason: "it cannot be read"})
+					handleLocked(file, nil, err)
+					continue
+				}
+				// the content which cannot be decoded was validated as far as possible
+				state.Notify(events.Event{Kind: events.FileFinished, FilePath: filePath, ErrorCount: len(validationErrors)})
+
+				handleLocked(file, &eccerror.ValidationErrors{FilePath: filePath, Errors: validationErrors}, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+	return ctx.Err()
+}
+
+// isDecodeError returns whether the error is about content which was read but cannot be decoded
+func isDecodeError(err error) bool {
+	var decodeErr *encoding.UnrecogizedEncodingError
+	return errors.As(err, &decodeErr)
+}
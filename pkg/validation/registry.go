@@ -0,0 +1,316 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	eccerror "github.com/editorconfig-checker/editorconfig-checker/v3/pkg/error"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/files"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/validation/validators"
+	// x-release-please-end
+)
+
+// Rule describes the check of a Validator
+type Rule struct {
+	// ID names the rule in the configuration and in the errors, like max-line-length
+	ID string
+	// Description explains what the rule checks
+	Description string
+	// Severity is the severity of the errors of the rule unless the Rules of the config set another one,
+	// config.SeverityError if empty
+	Severity string
+}
+
+// Validator is a check which is run on every file, it is either a LineValidator or a FileValidator
+type Validator interface {
+	// Rule returns the rule the errors of the Validator are reported as
+	Rule() Rule
+}
+
+// LineValidator checks every line of a file
+type LineValidator interface {
+	Validator
+	// ValidateLine returns the error of a line, or a ValidationError without a Message if there is none
+	ValidateLine(fileInformation files.FileInformation, config config.Config) eccerror.ValidationError
+}
+
+// FileValidator checks a file as a whole, after all of its lines were read
+type FileValidator interface {
+	Validator
+	// ValidateFile returns the error of a file, or a ValidationError without a Message if there is none
+	ValidateFile(fileSummary FileSummary, config config.Config) eccerror.ValidationError
+}
+
+// FileSummary is what is known about a file once all of its lines were read
+type FileSummary struct {
+	// FileInformation holds the path and the .editorconfig definition of the file,
+	// its Content are only the last two bytes of the file, which is enough to find its final newline
+	files.FileInformation
+	// EndOfLineCount counts the end of line characters of the file
+	EndOfLineCount validators.EndOfLineCount
+	// Charset is the detected charset of the file, empty if the file is not text
+	Charset string
+	// Truncated is set if only the first lines of the file were read, so its end is unknown
+	Truncated bool
+}
+
+// registry holds the registered validators in the order of their registration
+var registry struct {
+	lock       sync.RWMutex
+	validators []Validator
+	rules      map[string]Rule
+}
+
+// Register adds a validator, so its rule is checked on every file and can be set in the Rules of the config.
+// It panics if the validator is neither a LineValidator nor a FileValidator, or if its rule is already registered.
+func Register(validator Validator) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	rule := validator.Rule()
+	_, isLineValidator := validator.(LineValidator)
+	_, isFileValidator := validator.(FileValidator)
+	if !isLineValidator && !isFileValidator {
+		panic(fmt.Sprintf("validation: the validator of %s is neither a LineValidator nor a FileValidator", rule.ID))
+	}
+	if rule.ID == "" {
+		panic("validation: a rule needs an ID")
+	}
+	if _, ok := registry.rules[rule.ID]; ok {
+		panic(fmt.Sprintf("validation: the rule %s is already registered", rule.ID))
+	}
+
+	if registry.rules == nil {
+		registry.rules = make(map[string]Rule)
+	}
+	registry.rules[rule.ID] = rule
+	registry.validators = append(registry.validators, validator)
+}
+
+// Rules returns the rules of all registered validators in the order of their registration
+func Rules() []Rule {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+
+	rules := make([]Rule, 0, len(registry.validators))
+	for _, validator := range registry.validators {
+		rules = append(rules, validator.Rule())
+	}
+	return rules
+}
+
+// LookupRule returns the registered rule with the ID
+func LookupRule(id string) (Rule, bool) {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+
+	rule, ok := registry.rules[id]
+	return rule, ok
+}
+
+// CheckRules returns an error if the Rules of the config name a rule which is not registered
+// or set one to an unknown severity
+func CheckRules(cfg config.Config) error {
+	ids := make([]string, 0, len(cfg.Rules))
+	for id := range cfg.Rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	customRules, err := cfg.CachedCustomRules()
+	if err != nil {
+		return err
+	}
+	isCustomRule := make(map[string]bool, len(customRules))
+	for _, customRule := range customRules {
+		if _, ok := LookupRule(customRule.ID); ok || isCustomRule[customRule.ID] {
+			return fmt.Errorf("the custom rule %s is already defined", customRule.ID)
+		}
+		isCustomRule[customRule.ID] = true
+		switch customRule.Severity {
+		case "", config.SeverityWarning, config.SeverityError:
+		default:
+			return fmt.Errorf("unknown severity %q of the custom rule %s, it must be %s or %s", customRule.Severity, customRule.ID, config.SeverityWarning, config.SeverityError)
+		}
+	}
+
+	for _, id := range ids {
+		if _, ok := LookupRule(id); !ok && !isCustomRule[id] {
+			return unknownRule(id)
+		}
+		switch cfg.Rules[id] {
+		case config.SeverityOff, config.SeverityWarning, config.SeverityError:
+		default:
+			return fmt.Errorf("unknown severity %q of the rule %s, it must be %s, %s or %s", cfg.Rules[id], id, config.SeverityOff, config.SeverityWarning, config.SeverityError)
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(cfg.Disable.Rules)) {
+		if _, ok := LookupRule(id); !ok && !isCustomRule[id] && id != config.IndentSizeCheck {
+			return unknownRule(id)
+		}
+	}
+	return nil
+}
+
+// unknownRule returns the error of a rule which is not registered
+func unknownRule(id string) error {
+	var known []string
+	for _, rule := range Rules() {
+		known = append(known, rule.ID)
+	}
+	return fmt.Errorf("unknown rule %q, the rules are %s", id, strings.Join(known, ", "))
+}
+
+// enabledValidators returns the line and the file validators of a file whose rules are not set to off,
+// the ones of the custom rules of the config after the registered ones
+func enabledValidators(filePath string, cfg config.Config) ([]LineValidator, []FileValidator) {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+
+	var lineValidators []LineValidator
+	var fileValidators []FileValidator
+	for _, validator := range registry.validators {
+		if id := validator.Rule().ID; cfg.Rules[id] == config.SeverityOff || cfg.Disable.IsDisabled(id) {
+			continue
+		}
+		if lineValidator, ok := validator.(LineValidator); ok {
+			lineValidators = append(lineValidators, lineValidator)
+		}
+		if fileValidator, ok := validator.(FileValidator); ok {
+			fileValidators = append(fileValidators, fileValidator)
+		}
+	}
+
+	// invalid custom rules are reported by CheckRules
+	customRules, _ := cfg.CachedCustomRules()
+	if len(customRules) == 0 {
+		return lineValidators, fileValidators
+	}
+	relativeFilePath, err := files.GetRelativePath(filePath)
+	if err != nil {
+		relativeFilePath = filePath
+	}
+	for _, customRule := range customRules {
+		if cfg.Rules[customRule.ID] == config.SeverityOff || cfg.Disable.IsDisabled(customRule.ID) {
+			continue
+		}
+		if customRule.FilesMatcher != nil {
+			if matches, _ := customRule.FilesMatcher.Lookup(relativeFilePath, false); !matches {
+				continue
+			}
+		}
+		lineValidators = append(lineValidators, customRuleValidator{customRule})
+	}
+	return lineValidators, fileValidators
+}
+
+// withRule sets the rule and the severity of an error found by a validator
+func withRule(validationError eccerror.ValidationError, rule Rule, cfg config.Config) eccerror.ValidationError {
+	if validationError.Rule == "" {
+		validationError.Rule = rule.ID
+	}
+	validationError.Severity = rule.Severity
+	if severity, ok := cfg.Rules[rule.ID]; ok {
+		validationError.Severity = severity
+	}
+	return validationError
+}
+
+// the validators of the .editorconfig properties
+func init() {
+	Register(lineValidator{
+		rule:     Rule{ID: RuleTrimTrailingWhitespace, Description: "lines have no trailing whitespace if trim_trailing_whitespace is set"},
+		validate: ValidateTrailingWhitespace,
+	})
+	Register(lineValidator{
+		rule:     Rule{ID: RuleIndentation, Description: "lines are indented with the indent_style and indent_size"},
+		validate: ValidateIndentation,
+	})
+	Register(lineValidator{
+		rule:     Rule{ID: RuleMaxLineLength, Description: "lines are not longer than the max_line_length"},
+		validate: ValidateMaxLineLength,
+	})
+	Register(fileValidator{
+		rule: Rule{ID: RuleInsertFinalNewline, Description: "files end with a newline if insert_final_newline is set, and without one if it is false"},
+		validate: func(fileSummary FileSummary, config config.Config) eccerror.ValidationError {
+			// the cut off lines always end with a newline, unlike the file may
+			if fileSummary.Truncated {
+				return eccerror.ValidationError{}
+			}
+			return ValidateFinalNewline(fileSummary.FileInformation, config)
+		},
+	})
+	Register(fileValidator{
+		rule: Rule{ID: RuleEndOfLine, Description: "lines end with the end_of_line"},
+		validate: func(fileSummary FileSummary, config config.Config) eccerror.ValidationError {
+			// the lines which were not read may end differently
+			if fileSummary.Truncated {
+				return eccerror.ValidationError{}
+			}
+			return validateLineEndingCount(fileSummary.EndOfLineCount, fileSummary.FileInformation, config)
+		},
+	})
+	Register(fileValidator{
+		rule: Rule{ID: RuleCharset, Description: "files are encoded in the charset"},
+		validate: func(fileSummary FileSummary, config config.Config) eccerror.ValidationError {
+			return ValidateCharset(fileSummary.FileInformation, config, fileSummary.Charset)
+		},
+	})
+}
+
+// customRuleValidator is the LineValidator of a custom rule of the config
+type customRuleValidator struct {
+	customRule config.CompiledCustomRule
+}
+
+func (v customRuleValidator) Rule() Rule {
+	return Rule{ID: v.customRule.ID, Description: v.customRule.Message, Severity: v.customRule.Severity}
+}
+
+func (v customRuleValidator) ValidateLine(fileInformation files.FileInformation, config config.Config) eccerror.ValidationError {
+	if !v.customRule.Regexp.MatchString(fileInformation.Line) {
+		return eccerror.ValidationError{}
+	}
+	message := v.customRule.Message
+	if message == "" {
+		message = fmt.Sprintf("Line matches %s", v.customRule.Regex)
+	}
+	config.Logger.Verbose("%s error found in %s on line %d", v.customRule.ID, fileInformation.FilePath, fileInformation.LineNumber)
+	return eccerror.ValidationError{LineNumber: fileInformation.LineNumber + 1, Message: errors.New(message), LineHash: eccerror.HashLine(fileInformation.Line)}
+}
+
+// lineValidator is a LineValidator of a function
+type lineValidator struct {
+	rule     Rule
+	validate func(fileInformation files.FileInformation, config config.Config) eccerror.ValidationError
+}
+
+func (v lineValidator) Rule() Rule {
+	return v.rule
+}
+
+func (v lineValidator) ValidateLine(fileInformation files.FileInformation, config config.Config) eccerror.ValidationError {
+	return v.validate(fileInformation, config)
+}
+
+// fileValidator is a FileValidator of a function
+type fileValidator struct {
+	rule     Rule
+	validate func(fileSummary FileSummary, config config.Config) eccerror.ValidationError
+}
+
+func (v fileValidator) Rule() Rule {
+	return v.rule
+}
+
+func (v fileValidator) ValidateFile(fileSummary FileSummary, config config.Config) eccerror.ValidationError {
+	return v.validate(fileSummary, config)
+}
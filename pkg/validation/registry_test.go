@@ -0,0 +1,187 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	eccerror "github.com/editorconfig-checker/editorconfig-checker/v3/pkg/error"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/files"
+	// x-release-please-end
+)
+
+// markerValidator finds the lines containing a marker, which no other test file contains
+type markerValidator struct{}
+
+const marker = "registry-test-" + "marker"
+
+func (markerValidator) Rule() Rule {
+	return Rule{ID: "test-marker", Description: "lines do not contain the marker", Severity: config.SeverityWarning}
+}
+
+func (markerValidator) ValidateLine(fileInformation files.FileInformation, _ config.Config) eccerror.ValidationError {
+	if strings.Contains(fileInformation.Line, marker) {
+		return eccerror.ValidationError{LineNumber: fileInformation.LineNumber + 1, Message: errors.New("marker found")}
+	}
+	return eccerror.ValidationError{}
+}
+
+func init() {
+	Register(markerValidator{})
+}
+
+func TestRegister(t *testing.T) {
+	expectPanic := func(name string, validator Validator) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Register(%s): expected a panic", name)
+			}
+		}()
+		Register(validator)
+	}
+	expectPanic("duplicate", markerValidator{})
+	expectPanic("neither a LineValidator nor a FileValidator", ruleOnly{})
+
+	if rule, ok := LookupRule("test-marker"); !ok || rule.Severity != config.SeverityWarning {
+		t.Errorf("expected the registered rule, got %+v, %v", rule, ok)
+	}
+	if rules := Rules(); len(rules) == 0 || rules[0].ID != RuleTrimTrailingWhitespace {
+		t.Errorf("expected the rules in the order of their registration, got %+v", rules)
+	}
+}
+
+// ruleOnly is a Validator which validates nothing
+type ruleOnly struct{}
+
+func (ruleOnly) Rule() Rule {
+	return Rule{ID: "rule-only"}
+}
+
+func TestRegisteredValidator(t *testing.T) {
+	configuration := config.NewConfig(nil)
+	def := &editorconfig.Definition{Raw: map[string]string{"trim_trailing_whitespace": "true"}}
+	content := "first\n" + marker + " \n"
+
+	result := ValidateReader("marker.txt", strings.NewReader(content), *configuration, def)
+	if len(result) != 2 {
+		t.Fatalf("expected the trailing whitespace and the marker to be found, got %v", result)
+	}
+	if result[0].Rule != RuleTrimTrailingWhitespace || result[0].IsWarning() {
+		t.Errorf("expected the trailing whitespace to be an error, got %+v", result[0])
+	}
+	if result[1].Rule != "test-marker" || !result[1].IsWarning() {
+		t.Errorf("expected the marker to be a warning of its rule, got %+v", result[1])
+	}
+
+	// the config sets the severities of the rules or turns them off
+	configuration.Rules = map[string]string{"test-marker": config.SeverityError, RuleTrimTrailingWhitespace: config.SeverityOff}
+	result = ValidateReader("marker.txt", strings.NewReader(content), *configuration, def)
+	if len(result) != 1 || result[0].Rule != "test-marker" || result[0].IsWarning() {
+		t.Errorf("expected only the marker to be found as an error, got %+v", result)
+	}
+
+	// every registered rule can be disabled by its ID
+	configuration.Rules = nil
+	configuration.Disable = config.DisabledChecks{Rules: map[string]bool{"test-marker": true}}
+	result = ValidateReader("marker.txt", strings.NewReader(content), *configuration, def)
+	if len(result) != 1 || result[0].Rule != RuleTrimTrailingWhitespace {
+		t.Errorf("expected only the trailing whitespace to be found with the marker disabled, got %+v", result)
+	}
+}
+
+func TestCheckRules(t *testing.T) {
+	for rules, expectError := range map[string]bool{
+		"":                                   false,
+		RuleMaxLineLength + "=warning":       false,
+		"test-marker=off":                    false,
+		"unknown=warning":                    true,
+		RuleMaxLineLength + "=informational": true,
+	} {
+		configuration := config.Config{Rules: map[string]string{}}
+		if rule, severity, ok := strings.Cut(rules, "="); ok {
+			configuration.Rules[rule] = severity
+		}
+		if err := CheckRules(configuration); (err != nil) != expectError {
+			t.Errorf("CheckRules(%s): expected an error: %v, got %v", rules, expectError, err)
+		}
+	}
+
+	for id, expectError := range map[string]bool{
+		"test-marker":          false,
+		config.IndentSizeCheck: false,
+		"unknown":              true,
+	} {
+		if err := CheckRules(config.Config{Disable: config.DisabledChecks{Rules: map[string]bool{id: true}}}); (err != nil) != expectError {
+			t.Errorf("CheckRules(Disable %s): expected an error: %v, got %v", id, expectError, err)
+		}
+	}
+}
+
+func TestCustomRules(t *testing.T) {
+	configuration := config.NewConfig(nil)
+	configuration.CustomRules = []config.CustomRule{
+		{ID: "todo-without-ticket", Regex: `TODO($|[^(])`, Message: "TODO without a ticket", Severity: config.SeverityWarning},
+		{ID: "no-tabs-in-yaml", Regex: "\t", Message: "Tab character in YAML", Files: []string{"*.yml", "*.yaml"}},
+	}
+	def := &editorconfig.Definition{Raw: map[string]string{}}
+	content := "\tTODO fix\n\tTODO(ABC-1) fix\n\tTODO fix // editorconfig-checker-disable-line\n"
+
+	result := ValidateReader("config.yml", strings.NewReader(content), *configuration, def)
+	expected := []struct {
+		lineNumber int
+		rule       string
+		isWarning  bool
+	}{
+		{1, "todo-without-ticket", true},
+		{1, "no-tabs-in-yaml", false},
+		{2, "no-tabs-in-yaml", false},
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("expected %d errors, got %+v", len(expected), result)
+	}
+	for i, want := range expected {
+		if result[i].LineNumber != want.lineNumber || result[i].Rule != want.rule || result[i].IsWarning() != want.isWarning {
+			t.Errorf("expected %s on line %d, got %+v", want.rule, want.lineNumber, result[i])
+		}
+	}
+
+	// the glob patterns restrict the files a custom rule applies to
+	result = ValidateReader("main.go", strings.NewReader(content), *configuration, def)
+	if len(result) != 1 || result[0].Rule != "todo-without-ticket" || result[0].Message.Error() != "TODO without a ticket" {
+		t.Errorf("expected only the TODO to be found in main.go, got %+v", result)
+	}
+
+	// custom rules can be set like the other rules
+	configuration.Rules = map[string]string{"todo-without-ticket": config.SeverityOff}
+	if err := CheckRules(*configuration); err != nil {
+		t.Errorf("expected the Rules to accept the custom rule, got %v", err)
+	}
+	result = ValidateReader("main.go", strings.NewReader(content), *configuration, def)
+	if len(result) != 0 {
+		t.Errorf("expected the custom rule to be off, got %+v", result)
+	}
+}
+
+func TestCheckCustomRules(t *testing.T) {
+	for name, customRule := range map[string]config.CustomRule{
+		"no ID":            {Regex: "x"},
+		"invalid regex":    {ID: "invalid", Regex: "("},
+		"built-in ID":      {ID: RuleMaxLineLength, Regex: "x"},
+		"unknown severity": {ID: "unknown-severity", Regex: "x", Severity: "fatal"},
+	} {
+		configuration := config.Config{CustomRules: []config.CustomRule{customRule}}
+		if err := CheckRules(configuration); err == nil {
+			t.Errorf("CheckRules(%s): expected an error", name)
+		}
+	}
+
+	configuration := config.Config{CustomRules: []config.CustomRule{{ID: "twice", Regex: "x"}, {ID: "twice", Regex: "y"}}}
+	if err := CheckRules(configuration); err == nil {
+		t.Error("CheckRules(duplicate): expected an error")
+	}
+}
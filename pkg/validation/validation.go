@@ -2,19 +2,23 @@
 package validation
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"regexp"
-	"runtime"
 	"strconv"
 	"strings"
-	"sync"
 
 	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/cache"
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/encoding"
-	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/error"
+	eccerror "github.com/editorconfig-checker/editorconfig-checker/v3/pkg/error"
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/files"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/run"
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/validation/validators"
 
 	// x-release-please-end
@@ -22,21 +26,41 @@ import (
 	"github.com/editorconfig/editorconfig-core-go/v2"
 )
 
+// The rules a ValidationError can be found by, named like their --disable-* flags
+const (
+	RuleCharset                = "charset"
+	RuleEndOfLine              = "end-of-line"
+	RuleIndentation            = "indentation"
+	RuleInsertFinalNewline     = "insert-final-newline"
+	RuleMaxLineLength          = "max-line-length"
+	RuleTrimTrailingWhitespace = "trim-trailing-whitespace"
+)
+
 // keep synced with /pkg/config/config.go#L59
-var textRegexes = []string{
-	"^text/",
-	"application/octet-stream",
-	"^application/ecmascript$",
-	"^application/json$",
-	"^application/x-ndjson$",
-	"^application/xml$",
-	"\\+json",
-	"\\+xml$",
+var textRegexes = []*regexp.Regexp{
+	regexp.MustCompile("^text/"),
+	regexp.MustCompile("application/octet-stream"),
+	regexp.MustCompile("^application/ecmascript$"),
+	regexp.MustCompile("^application/json$"),
+	regexp.MustCompile("^application/x-ndjson$"),
+	regexp.MustCompile("^application/xml$"),
+	regexp.MustCompile("\\+json"),
+	regexp.MustCompile("\\+xml$"),
+}
+
+// isText returns whether the content of the mime type is text, which is decoded before it is validated
+func isText(mime string) bool {
+	for _, regex := range textRegexes {
+		if regex.MatchString(mime) {
+			return true
+		}
+	}
+	return false
 }
 
 // ValidateFile Validates a single file and returns the errors
 // Note: This function is not thread safe, so it should not be called concurrently
-func ValidateFile(filePath string, config config.Config) []error.ValidationError {
+func ValidateFile(filePath string, config config.Config) []eccerror.ValidationError {
 	// idiomatic Go allows empty struct
 	if config.EditorconfigConfig == nil {
 		config.EditorconfigConfig = &editorconfig.Config{}
@@ -56,7 +80,42 @@ func ValidateFile(filePath string, config config.Config) []error.ValidationError
 }
 
 // ValidateFileWithDefinition Validates a single file with a given editorconfig definition and returns the errors
-func ValidateFileWithDefinition(filePath string, config config.Config, def *editorconfig.Definition) []error.ValidationError {
+func ValidateFileWithDefinition(filePath string, config config.Config, def *editorconfig.Definition) []eccerror.ValidationError {
+	validationErrors, err := validateDiscoveredFile(files.File{Path: filePath}, config, nil, def)
+	if err != nil {
+		config.Logger.Error("%v", err.Error())
+	}
+	return validationErrors
+}
+
+// ValidateContentWithDefinition Validates the content of a file with a given editorconfig definition and returns the errors
+// The filePath is only used for messages, the file itself is not read
+func ValidateContentWithDefinition(filePath string, rawFileContent []byte, config config.Config, def *editorconfig.Definition) []eccerror.ValidationError {
+	return ValidateReader(filePath, bytes.NewReader(rawFileContent), config, def)
+}
+
+// ValidateContent validates the content like ValidateContentWithDefinition,
+// but the error is returned along with the errors found instead of being logged if the content cannot be read or decoded completely
+func ValidateContent(filePath string, rawFileContent []byte, config config.Config, def *editorconfig.Definition) ([]eccerror.ValidationError, error) {
+	return validateReader(filePath, bytes.NewReader(rawFileContent), "", false, config, def)
+}
+
+// ValidateReader Validates the content of a file read from a reader with a given editorconfig definition and returns the errors
+// The content is decoded and validated one line at a time, so apart from the current line it is not held in memory.
+// The filePath is only used for messages, the file itself is not read
+func ValidateReader(filePath string, reader io.Reader, config config.Config, def *editorconfig.Definition) []eccerror.ValidationError {
+	validationErrors, err := validateReader(filePath, reader, "", false, config, def)
+	if err != nil {
+		config.Logger.Error("%v", err.Error())
+	}
+	return validationErrors
+}
+
+// validateReader validates the content read from a reader like ValidateReader,
+// the contentType is detected from the content unless it is passed.
+// The content is truncated if it is only the first lines of the file.
+// The error is returned along with the errors found if the content cannot be read or decoded completely.
+func validateReader(filePath string, reader io.Reader, contentType string, truncated bool, config config.Config, def *editorconfig.Definition) ([]eccerror.ValidationError, error) {
 	const directivePrefix = "editorconfig-checker-"
 	const directiveDisable = directivePrefix + "disable"
 	const directiveDisableFile = directivePrefix + "disable-file"
@@ -64,57 +123,65 @@ func ValidateFileWithDefinition(filePath string, config config.Config, def *edit
 	const directiveDisableNextLine = directivePrefix + "disable-next-line"
 	const directiveEnable = directivePrefix + "enable"
 
-	var validationErrors []error.ValidationError
+	var validationErrors []eccerror.ValidationError
 	var isDisabled bool = false
 
-	var charset string
-
-	rawFileContent, err := os.ReadFile(filePath)
-	if err != nil {
-		panic(err)
-	}
-	fileContent := string(rawFileContent)
-	mime, err := files.GetContentTypeBytes(bytes.NewReader(rawFileContent))
-	if err != nil {
-		panic(err)
-	}
-	for _, regex := range textRegexes {
-		match, _ := regexp.MatchString(regex, mime)
-		if match {
-			fileContent, charset, err = encoding.Decode(rawFileContent)
-			if err != nil {
-				config.Logger.Error("Could not decode the %q encoded file %q: %s", charset, filePath, err.Error())
-			}
-			break
+	// the sample is shared by the content type and the encoding detection
+	sampleReader := bufio.NewReaderSize(reader, encoding.SampleSize)
+	var err error
+	if contentType == "" {
+		// a sample shorter than the SampleSize is the whole content, which is no error
+		sample, _ := sampleReader.Peek(encoding.SampleSize)
+		contentType, err = files.GetContentTypeBytes(bytes.NewReader(sample))
+		if err != nil {
+			return nil, fmt.Errorf("Could not get the ContentType of the file %q: %w", filePath, err)
 		}
 	}
-	lines := files.ReadLines(fileContent)
-
-	// return if first line contains editorconfig-checker-disable-file
-	if len(lines) == 0 || strings.Contains(lines[0], directiveDisableFile) {
-		return validationErrors
-	}
 
-	fileInformation := files.FileInformation{Content: fileContent, FilePath: filePath, Editorconfig: def}
-	validationError := ValidateFinalNewline(fileInformation, config)
-	if validationError.Message != nil {
-		validationErrors = append(validationErrors, validationError)
+	var contentReader io.Reader = sampleReader
+	var decoder *encoding.Reader
+	// the content which cannot be decoded is validated as far as possible
+	var decodeErr error
+	if isText(contentType) {
+		decoder, err = encoding.NewReader(sampleReader)
+		if err != nil {
+			decodeErr = fmt.Errorf("Could not decode the %q encoded file %q: %w", decoder.Charset(), filePath, err)
+		}
+		contentReader = decoder
 	}
 
-	fileInformation = files.FileInformation{Content: fileContent, FilePath: filePath, Editorconfig: def}
-	validationError = ValidateLineEnding(fileInformation, config)
-	if validationError.Message != nil {
-		validationErrors = append(validationErrors, validationError)
-	}
+	lineValidators, fileValidators := enabledValidators(filePath, config)
 
-	fileInformation = files.FileInformation{Content: fileContent, FilePath: filePath, Editorconfig: def}
-	validationError = ValidateCharset(fileInformation, config, charset)
-	if validationError.Message != nil {
-		validationErrors = append(validationErrors, validationError)
-	}
+	// the checks of the whole file run on the state gathered while reading the lines
+	var endOfLineCount validators.EndOfLineCount
+	var contentEnd string
 
+	lineReader := bufio.NewReader(contentReader)
+	var lineBuffer []byte
 	var disableNextLineFound bool // used to ignore the line when editorconfig-checker-disable-next-line was found on previous line
-	for lineNumber, line := range lines {
+	for lineNumber := 0; ; lineNumber++ {
+		lineBuffer, err = files.ReadLine(lineReader, lineBuffer)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return validationErrors, fmt.Errorf("Could not read the file %q: %w", filePath, err)
+		}
+		if len(lineBuffer) == 0 {
+			// return if the file has no lines
+			if lineNumber == 0 {
+				return validationErrors, decodeErr
+			}
+			break
+		}
+
+		rawLine := string(lineBuffer)
+		endOfLineCount.Add(rawLine)
+		contentEnd = lastBytes(contentEnd+rawLine, 2)
+		line := strings.TrimSuffix(strings.TrimSuffix(rawLine, "\n"), "\r")
+
+		// return if first line contains editorconfig-checker-disable-file
+		if lineNumber == 0 && strings.Contains(line, directiveDisableFile) {
+			return validationErrors, nil
+		}
+
 		// search for editorconfig-checker-enable
 		// but only if not disabled for performance reasons
 		if isDisabled && strings.Contains(line, directiveEnable) {
@@ -167,53 +234,75 @@ func ValidateFileWithDefinition(filePath string, config config.Config, def *edit
 			}
 		}
 
-		fileInformation = files.FileInformation{Line: line, FilePath: filePath, LineNumber: lineNumber, Editorconfig: def}
-		validationError = ValidateTrailingWhitespace(fileInformation, config)
-		if validationError.Message != nil {
-			validationErrors = append(validationErrors, validationError)
-		}
-
-		validationError = ValidateIndentation(fileInformation, config)
-		if validationError.Message != nil {
-			validationErrors = append(validationErrors, validationError)
+		fileInformation := files.FileInformation{Line: line, FilePath: filePath, LineNumber: lineNumber, Editorconfig: def}
+		for _, validator := range lineValidators {
+			if validationError := validator.ValidateLine(fileInformation, config); validationError.Message != nil {
+				validationErrors = append(validationErrors, withRule(validationError, validator.Rule(), config))
+			}
 		}
+	}
 
-		validationError = ValidateMaxLineLength(fileInformation, config)
-		if validationError.Message != nil {
-			validationErrors = append(validationErrors, validationError)
+	// only the end of the content is needed to find its final newline
+	fileSummary := FileSummary{
+		FileInformation: files.FileInformation{Content: contentEnd, FilePath: filePath, Editorconfig: def},
+		EndOfLineCount:  endOfLineCount,
+		Truncated:       truncated,
+	}
+	if decoder != nil {
+		fileSummary.Charset = decoder.Charset()
+	}
+	var fileValidationErrors []eccerror.ValidationError
+	for _, validator := range fileValidators {
+		if validationError := validator.ValidateFile(fileSummary, config); validationError.Message != nil {
+			fileValidationErrors = append(fileValidationErrors, withRule(validationError, validator.Rule(), config))
 		}
 	}
 
-	return validationErrors
+	return append(fileValidationErrors, validationErrors...), decodeErr
+}
+
+// lastBytes returns the last n bytes of a string
+func lastBytes(content string, n int) string {
+	if len(content) <= n {
+		return content
+	}
+	return content[len(content)-n:]
 }
 
 // ValidateFinalNewline runs the final newline validator and processes the error into the proper type
-func ValidateFinalNewline(fileInformation files.FileInformation, config config.Config) error.ValidationError {
+func ValidateFinalNewline(fileInformation files.FileInformation, config config.Config) eccerror.ValidationError {
 	if currentError := validators.FinalNewline(
 		fileInformation.Content,
 		fileInformation.Editorconfig.Raw["insert_final_newline"],
-		fileInformation.Editorconfig.Raw["end_of_line"]); !config.Disable.InsertFinalNewline && currentError != nil {
+		fileInformation.Editorconfig.Raw["end_of_line"]); !config.Disable.IsDisabled(RuleInsertFinalNewline) && currentError != nil {
 		config.Logger.Verbose("Final newline error found in %s", fileInformation.FilePath)
-		return error.ValidationError{LineNumber: -1, Message: currentError}
+		return eccerror.ValidationError{LineNumber: -1, Message: currentError, Rule: RuleInsertFinalNewline}
 	}
 
-	return error.ValidationError{}
+	return eccerror.ValidationError{}
 }
 
 // ValidateLineEnding runs the line ending validator and processes the error into the proper type
-func ValidateLineEnding(fileInformation files.FileInformation, config config.Config) error.ValidationError {
-	if currentError := validators.LineEnding(
-		fileInformation.Content,
-		fileInformation.Editorconfig.Raw["end_of_line"]); !config.Disable.EndOfLine && currentError != nil {
+func ValidateLineEnding(fileInformation files.FileInformation, config config.Config) eccerror.ValidationError {
+	var endOfLineCount validators.EndOfLineCount
+	endOfLineCount.Add(fileInformation.Content)
+	return validateLineEndingCount(endOfLineCount, fileInformation, config)
+}
+
+// validateLineEndingCount runs the line ending validator on the number of end of line characters of a file
+func validateLineEndingCount(endOfLineCount validators.EndOfLineCount, fileInformation files.FileInformation, config config.Config) eccerror.ValidationError {
+	if currentError := validators.LineEndingCount(
+		endOfLineCount,
+		fileInformation.Editorconfig.Raw["end_of_line"]); !config.Disable.IsDisabled(RuleEndOfLine) && currentError != nil {
 		config.Logger.Verbose("Line ending error found in %s", fileInformation.FilePath)
-		return error.ValidationError{LineNumber: -1, Message: currentError}
+		return eccerror.ValidationError{LineNumber: -1, Message: currentError, Rule: RuleEndOfLine}
 	}
 
-	return error.ValidationError{}
+	return eccerror.ValidationError{}
 }
 
 // ValidateIndentation runs the Indentation validator and processes the error into the proper type
-func ValidateIndentation(fileInformation files.FileInformation, config config.Config) error.ValidationError {
+func ValidateIndentation(fileInformation files.FileInformation, config config.Config) eccerror.ValidationError {
 	var indentSize int
 	indentSize, err := strconv.Atoi(fileInformation.Editorconfig.Raw["indent_size"])
 	// Set indentSize to zero if there is no indentSize set
@@ -224,109 +313,186 @@ func ValidateIndentation(fileInformation files.FileInformation, config config.Co
 	if currentError := validators.Indentation(
 		fileInformation.Line,
 		fileInformation.Editorconfig.Raw["indent_style"],
-		indentSize, config); !config.Disable.Indentation && currentError != nil {
+		indentSize, config); !config.Disable.IsDisabled(RuleIndentation) && currentError != nil {
 		config.Logger.Verbose("Indentation error found in %s on line %d", fileInformation.FilePath, fileInformation.LineNumber)
-		return error.ValidationError{LineNumber: fileInformation.LineNumber + 1, Message: currentError}
+		return eccerror.ValidationError{LineNumber: fileInformation.LineNumber + 1, Message: currentError, Rule: RuleIndentation, LineHash: eccerror.HashLine(fileInformation.Line)}
 	}
 
-	return error.ValidationError{}
+	return eccerror.ValidationError{}
 }
 
 // ValidateTrailingWhitespace runs the TrailingWhitespace validator and processes the error into the proper type
-func ValidateTrailingWhitespace(fileInformation files.FileInformation, config config.Config) error.ValidationError {
+func ValidateTrailingWhitespace(fileInformation files.FileInformation, config config.Config) eccerror.ValidationError {
 	if currentError := validators.TrailingWhitespace(
 		fileInformation.Line,
-		fileInformation.Editorconfig.Raw["trim_trailing_whitespace"] == "true"); !config.Disable.TrimTrailingWhitespace && currentError != nil {
+		fileInformation.Editorconfig.Raw["trim_trailing_whitespace"] == "true"); !config.Disable.IsDisabled(RuleTrimTrailingWhitespace) && currentError != nil {
 		config.Logger.Verbose("Trailing whitespace error found in %s on line %d", fileInformation.FilePath, fileInformation.LineNumber)
-		return error.ValidationError{LineNumber: fileInformation.LineNumber + 1, Message: currentError}
+		return eccerror.ValidationError{LineNumber: fileInformation.LineNumber + 1, Message: currentError, Rule: RuleTrimTrailingWhitespace, LineHash: eccerror.HashLine(fileInformation.Line)}
 	}
 
-	return error.ValidationError{}
+	return eccerror.ValidationError{}
 }
 
 // ValidateMaxLineLength runs the max line length validator and processes the error into the proper type
-func ValidateMaxLineLength(fileInformation files.FileInformation, config config.Config) error.ValidationError {
+func ValidateMaxLineLength(fileInformation files.FileInformation, config config.Config) eccerror.ValidationError {
 	maxLineLength, err := strconv.Atoi(fileInformation.Editorconfig.Raw["max_line_length"])
 	if err != nil {
-		return error.ValidationError{}
+		return eccerror.ValidationError{}
 	}
 
 	charSet := fileInformation.Editorconfig.Raw["charset"]
 
-	if currentError := validators.MaxLineLength(fileInformation.Line, maxLineLength, charSet); !config.Disable.MaxLineLength && currentError != nil {
+	if currentError := validators.MaxLineLength(fileInformation.Line, maxLineLength, charSet); !config.Disable.IsDisabled(RuleMaxLineLength) && currentError != nil {
 		config.Logger.Verbose("Max line length error found in %s on %d", fileInformation.FilePath, fileInformation.LineNumber)
-		return error.ValidationError{LineNumber: fileInformation.LineNumber + 1, Message: currentError}
+		return eccerror.ValidationError{LineNumber: fileInformation.LineNumber + 1, Message: currentError, Rule: RuleMaxLineLength, LineHash: eccerror.HashLine(fileInformation.Line)}
 	}
 
-	return error.ValidationError{}
+	return eccerror.ValidationError{}
 }
 
 // ValidateCharset runs the charset validator and processes the error into the proper type
-func ValidateCharset(fileInformation files.FileInformation, config config.Config, charset string) error.ValidationError {
+func ValidateCharset(fileInformation files.FileInformation, config config.Config, charset string) eccerror.ValidationError {
 	if currentError := validators.Charset(
 		fileInformation.Editorconfig.Raw["charset"],
 		charset,
-		config); !config.Disable.Charset && currentError != nil {
+		config); !config.Disable.IsDisabled(RuleCharset) && currentError != nil {
 		config.Logger.Verbose("Wrong charset found in %s", fileInformation.FilePath)
-		return error.ValidationError{LineNumber: -1, Message: currentError}
+		return eccerror.ValidationError{LineNumber: -1, Message: currentError, Rule: RuleCharset}
 	}
 
-	return error.ValidationError{}
+	return eccerror.ValidationError{}
 }
 
-// ProcessValidation Validates all files and returns an array of validation errors
-func ProcessValidation(files []string, config config.Config) []error.ValidationErrors {
-	// idiomatic Go allows empty struct
-	if config.EditorconfigConfig == nil {
-		config.EditorconfigConfig = &editorconfig.Config{}
+// validateFile validates a single file with what was found out about it while discovering it,
+// unless the cache holds its errors of a previous run with the same content and editorconfig definition.
+// A file on the file system is looked up by its stamp, and its content is hashed while it is validated,
+// so it is read only once.
+// The error is returned along with the errors found if the file cannot be read or decoded completely.
+func validateFile(file files.File, config config.Config, state *run.State, def *editorconfig.Definition) ([]eccerror.ValidationError, error) {
+	if state == nil || state.Cache == nil {
+		return validateDiscoveredFile(file, config, state, def)
 	}
+	filePath := file.Path
 
-	var (
-		validationErrors = make([]*error.ValidationErrors, len(files))
-		wg               sync.WaitGroup
-		lock             sync.Mutex
-	)
-	// Limit the number of concurrent goroutines to the number of CPUs
-	limiter := make(chan struct{}, runtime.NumCPU())
-
-	for i, filePath := range files {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			// Wait for a slot to be available in the limiter, and release it via defer
-			limiter <- struct{}{}
-			defer func() { <-limiter }()
-
-			config.Logger.Verbose("Validate %s", filePath)
-
-			// EditorconfigConfig isn't thread safe, so we need to acquire a lock
-			lock.Lock()
-			def, warnings, err := config.EditorconfigConfig.LoadGraceful(filePath)
-			lock.Unlock()
-			if err != nil {
-				config.Logger.Error("cannot load %s as .editorconfig: %s", filePath, err)
-				return
-			}
-			if warnings != nil {
-				config.Logger.Warning("%v", warnings.Error())
-			}
-			errors := ValidateFileWithDefinition(filePath, config, def)
+	// the cache is independent of the location of the working directory
+	cachePath, err := files.GetRelativePath(filePath)
+	if err != nil {
+		cachePath = filePath
+	}
 
-			lock.Lock()
-			validationErrors[i] = &error.ValidationErrors{FilePath: filePath, Errors: errors}
-			lock.Unlock()
-		}()
+	if file.Content == nil && state.IsInMemory(filePath) {
+		// a file which is not on the file system has no stamp, its content is hashed as a whole
+		if file.Content, file.Truncated, err = readContent(filePath, config, state); err != nil {
+			return nil, err
+		}
 	}
 
-	wg.Wait()
+	if file.Content != nil {
+		// reading from memory cannot fail
+		key, _ := state.Cache.Key(bytes.NewReader(file.Content), def.Raw)
+		if cachedErrors, ok := state.Cache.Get(cachePath, key); ok {
+			config.Logger.Verbose("Using the cached result of %s", filePath)
+			return fromCache(cachedErrors), nil
+		}
 
-	// Remove all nil values
-	result := make([]error.ValidationErrors, 0, len(validationErrors))
-	for _, validationError := range validationErrors {
-		if validationError != nil {
-			result = append(result, *validationError)
+		validationErrors, err := validateDiscoveredFile(file, config, state, def)
+		if err != nil {
+			// the errors of a file which cannot be validated completely are not cached
+			return validationErrors, err
 		}
+		state.Cache.Put(cachePath, key, toCache(validationErrors))
+		return validationErrors, nil
+	}
+
+	// the stamp is taken before the file is read, so a modification while it is read changes the stamp
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+	stamp := state.Cache.Stamp(fileInfo.Size(), fileInfo.ModTime(), def.Raw)
+	if cachedErrors, ok := state.Cache.GetStamped(cachePath, stamp); ok {
+		config.Logger.Verbose("Using the cached result of %s", filePath)
+		return fromCache(cachedErrors), nil
+	}
+
+	reader, truncated, err := files.OpenWithTruncation(filePath, config, state)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	keyHash := state.Cache.NewKeyHash(def.Raw)
+	content := io.TeeReader(reader, keyHash)
+	validationErrors, err := validateReader(filePath, content, file.ContentType, truncated, config, def)
+	if err != nil {
+		return validationErrors, err
+	}
+	// the validation stops early for a disabled file, whose rest is part of the key as well
+	if _, err := io.Copy(io.Discard, content); err != nil {
+		return validationErrors, err
+	}
+	state.Cache.PutStamped(cachePath, keyHash.Key(), stamp, toCache(validationErrors))
+	return validationErrors, nil
+}
+
+// readContent reads the content of a file to check, and returns whether it is only the first lines of the file
+func readContent(filePath string, config config.Config, state *run.State) ([]byte, bool, error) {
+	reader, truncated, err := files.OpenWithTruncation(filePath, config, state)
+	if err != nil {
+		return nil, false, err
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err == nil && content == nil {
+		// an empty file is held in memory just as well
+		content = []byte{}
+	}
+	return content, truncated, err
+}
+
+// toCache returns the validation errors as they are stored in the cache
+func toCache(validationErrors []eccerror.ValidationError) []cache.Error {
+	cachedErrors := make([]cache.Error, 0, len(validationErrors))
+	for _, validationError := range validationErrors {
+		cachedErrors = append(cachedErrors, cache.Error{
+			LineNumber: validationError.LineNumber,
+			Message:    validationError.Message.Error(),
+			Rule:       validationError.Rule,
+			LineHash:   validationError.LineHash,
+			Severity:   validationError.Severity,
+		})
+	}
+	return cachedErrors
+}
+
+// fromCache returns the validation errors stored in the cache
+func fromCache(cachedErrors []cache.Error) []eccerror.ValidationError {
+	validationErrors := make([]eccerror.ValidationError, 0, len(cachedErrors))
+	for _, cachedError := range cachedErrors {
+		validationErrors = append(validationErrors, eccerror.ValidationError{
+			LineNumber: cachedError.LineNumber,
+			Message:    errors.New(cachedError.Message),
+			Rule:       cachedError.Rule,
+			LineHash:   cachedError.LineHash,
+			Severity:   cachedError.Severity,
+		})
+	}
+	return validationErrors
+}
+
+// validateDiscoveredFile validates a single file with the ContentType detected while discovering it, if any,
+// and its Content if it is held in memory
+func validateDiscoveredFile(file files.File, config config.Config, state *run.State, def *editorconfig.Definition) ([]eccerror.ValidationError, error) {
+	if file.Content != nil {
+		return validateReader(file.Path, bytes.NewReader(file.Content), file.ContentType, file.Truncated, config, def)
+	}
+
+	reader, truncated, err := files.OpenWithTruncation(file.Path, config, state)
+	if err != nil {
+		return nil, err
 	}
+	defer reader.Close()
 
-	return result
+	return validateReader(file.Path, reader, file.ContentType, truncated, config, def)
 }
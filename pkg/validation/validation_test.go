@@ -1,10 +1,28 @@
 package validation
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/editorconfig/editorconfig-core-go/v2"
+	"golang.org/x/text/encoding/simplifiedchinese"
+
 	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/cache"
 	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/encoding"
+	eccerror "github.com/editorconfig-checker/editorconfig-checker/v3/pkg/error"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/events"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/files"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/run"
 	// x-release-please-end
 )
 
@@ -33,6 +51,93 @@ func TestProcessValidation(t *testing.T) {
 	}
 }
 
+func TestProcessValidationEvents(t *testing.T) {
+	var lock sync.Mutex
+	received := make(map[string][]events.Event)
+	configuration := config.NewConfig(nil)
+	state := run.New()
+	state.Events = events.SubscriberFunc(func(event events.Event) {
+		lock.Lock()
+		defer lock.Unlock()
+		received[event.FilePath] = append(received[event.FilePath], event)
+	})
+
+	_, err := ProcessValidationContext(context.Background(), []string{"./../../testfiles/empty-file.txt", "./../../testfiles/wrong-file.txt"}, *configuration, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for filePath, errorCount := range map[string]int{"./../../testfiles/empty-file.txt": 0, "./../../testfiles/wrong-file.txt": 1} {
+		expected := []events.Event{
+			{Kind: events.FileStarted, FilePath: filePath},
+			{Kind: events.FileFinished, FilePath: filePath, ErrorCount: errorCount},
+		}
+		if !reflect.DeepEqual(received[filePath], expected) {
+			t.Errorf("expected the events %+v, got %+v", expected, received[filePath])
+		}
+	}
+}
+
+func TestProcessValidationDecodeError(t *testing.T) {
+	var received []events.Event
+	configuration := config.NewConfig(nil)
+	state := run.New()
+	state.Events = events.SubscriberFunc(func(event events.Event) {
+		received = append(received, event)
+	})
+
+	// GB2312 is detected, but there is no decoder for it
+	content, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(strings.Repeat("中华人民共和国的首都是北京。这是一个测试文件的内容。\n", 5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	filePath := filepath.Join(t.TempDir(), "gb2312.txt")
+	if err := os.WriteFile(filePath, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// a file which was read completely, but cannot be decoded, is finished with an error instead of being skipped
+	validationErrors, fileErrors, err := ProcessDiscoveredValidation(context.Background(), []files.File{{Path: filePath}}, *configuration, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(validationErrors) != 1 || len(validationErrors[0].Errors) != 0 {
+		t.Errorf("expected the file to be validated without errors, got %v", validationErrors)
+	}
+	var decodeErr *encoding.UnrecogizedEncodingError
+	if len(fileErrors) != 1 || fileErrors[0].FilePath != filePath || !errors.As(fileErrors[0], &decodeErr) {
+		t.Errorf("expected a decode error of %s, got %v", filePath, fileErrors)
+	}
+	expected := []events.Event{
+		{Kind: events.FileStarted, FilePath: filePath},
+		{Kind: events.FileFinished, FilePath: filePath},
+	}
+	if !reflect.DeepEqual(received, expected) {
+		t.Errorf("expected the events %+v, got %+v", expected, received)
+	}
+}
+
+func TestStreamValidation(t *testing.T) {
+	configuration := config.NewConfig(nil)
+	filePaths := []string{"./../../testfiles/empty-file.txt", "./../../testfiles/wrong-file.txt", "./../../testfiles/disabled-file.ext"}
+
+	handled := make([]int, len(filePaths))
+	errorCount := 0
+	err := StreamValidation(context.Background(), filePaths, *configuration, nil, func(index int, fileErrors eccerror.ValidationErrors) {
+		handled[index]++
+		if fileErrors.FilePath != filePaths[index] {
+			t.Errorf("expected the errors of %s for the index %d, got the ones of %s", filePaths[index], index, fileErrors.FilePath)
+		}
+		errorCount += len(fileErrors.Errors)
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(handled, []int{1, 1, 1}) || errorCount != 1 {
+		t.Errorf("expected every file to be handled once with one error in total, got %v and %d errors", handled, errorCount)
+	}
+}
+
 func TestValidateFile(t *testing.T) {
 	configuration := config.NewConfig(nil)
 	configuration.Verbose = true
@@ -178,3 +283,139 @@ func TestValidateFile(t *testing.T) {
 		t.Error("Should have no errors when validating valid file, got", result)
 	}
 }
+
+func TestValidateReader(t *testing.T) {
+	configuration := config.NewConfig(nil)
+	def := &editorconfig.Definition{Raw: map[string]string{
+		"trim_trailing_whitespace": "true",
+		"end_of_line":              "lf",
+		"insert_final_newline":     "true",
+	}}
+
+	// lines longer than the buffer of a bufio.Scanner are checked, too
+	longLine := strings.Repeat("a", 100*1024)
+	content := longLine + " \n" + longLine + "\n" + "last \r\n" + "no final newline"
+	result := ValidateReader("long-lines.txt", strings.NewReader(content), *configuration, def)
+
+	expected := []struct {
+		lineNumber int
+		rule       string
+	}{
+		{-1, RuleInsertFinalNewline},
+		{-1, RuleEndOfLine},
+		{1, RuleTrimTrailingWhitespace},
+		{3, RuleTrimTrailingWhitespace},
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("ValidateReader(long lines): expected %d errors, got %v", len(expected), result)
+	}
+	for i, want := range expected {
+		if result[i].LineNumber != want.lineNumber || result[i].Rule != want.rule {
+			t.Errorf("ValidateReader(long lines): expected %s on line %d, got %+v", want.rule, want.lineNumber, result[i])
+		}
+	}
+
+	result = ValidateReader("valid.txt", strings.NewReader(longLine+"\n"+longLine+"\n"), *configuration, def)
+	if len(result) != 0 {
+		t.Errorf("ValidateReader(valid): expected no errors, got %v", result)
+	}
+}
+
+func TestValidateDiscoveredFile(t *testing.T) {
+	configuration := config.NewConfig(nil)
+	def := &editorconfig.Definition{Raw: map[string]string{"trim_trailing_whitespace": "true"}}
+
+	// the content read while discovering the file is validated, the file itself is not read again
+	file := files.File{Path: "does-not-exist.txt", ContentType: "text/plain", Content: []byte("trailing \nvalid\n")}
+	result, err := validateFile(file, *configuration, nil, def)
+	if err != nil || len(result) != 1 || result[0].LineNumber != 1 || result[0].Rule != RuleTrimTrailingWhitespace {
+		t.Errorf("validateFile(discovered content): expected trailing whitespace on line 1, got %v, %v", result, err)
+	}
+
+	// without content the file is read, and its ContentType is detected if it was not discovered
+	result, err = validateFile(files.File{Path: "./../../testfiles/wrong-file.txt"}, *configuration, nil, &editorconfig.Definition{Raw: map[string]string{"indent_style": "tab"}})
+	if err != nil || len(result) != 1 {
+		t.Errorf("validateFile(wrong-file.txt): expected one error, got %v, %v", result, err)
+	}
+
+	// a file which cannot be read, like one deleted since it was found, returns an error instead of panicking
+	result, err = validateFile(files.File{Path: filepath.Join(t.TempDir(), "deleted.txt")}, *configuration, nil, def)
+	if !errors.Is(err, fs.ErrNotExist) || result != nil {
+		t.Errorf("validateFile(deleted.txt): expected a not exist error, got %v, %v", result, err)
+	}
+}
+
+func TestValidateFileCache(t *testing.T) {
+	configuration := config.NewConfig(nil)
+	state := run.New()
+	state.Cache = cache.Load(t.TempDir(), "salt")
+	t.Chdir(t.TempDir())
+	def := &editorconfig.Definition{Raw: map[string]string{"trim_trailing_whitespace": "true"}}
+
+	content := []byte("trailing \nvalid\n")
+	filePath := "file.txt"
+	if err := os.WriteFile(filePath, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the content of a file on disk is hashed while it is validated, so the key matches the one of the same content held in memory
+	result, err := validateFile(files.File{Path: filePath, ContentType: "text/plain"}, *configuration, state, def)
+	if err != nil || len(result) != 1 || result[0].Rule != RuleTrimTrailingWhitespace {
+		t.Errorf("validateFile(on disk): expected trailing whitespace on line 1, got %v, %v", result, err)
+	}
+	key, err := state.Cache.Key(bytes.NewReader(content), def.Raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cachedErrors, ok := state.Cache.Get(filePath, key); !ok || len(cachedErrors) != 1 {
+		t.Errorf("expected the error to be cached with the key of the content, got %v, %v", cachedErrors, ok)
+	}
+
+	// a file whose stamp did not change is not read again
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filePath, []byte("trimmed!!\nvalid\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filePath, fileInfo.ModTime(), fileInfo.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	result, err = validateFile(files.File{Path: filePath, ContentType: "text/plain"}, *configuration, state, def)
+	if err != nil || len(result) != 1 {
+		t.Errorf("validateFile(same stamp): expected the cached error, got %v, %v", result, err)
+	}
+
+	// the content after a disable-file directive is part of the key as well
+	disabled := []byte("editorconfig-checker-disable-file\ntrailing \n")
+	if err := os.WriteFile(filePath, disabled, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = validateFile(files.File{Path: filePath, ContentType: "text/plain"}, *configuration, state, def)
+	if err != nil || len(result) != 0 {
+		t.Errorf("validateFile(disabled): expected no errors, got %v, %v", result, err)
+	}
+	if key, err = state.Cache.Key(bytes.NewReader(disabled), def.Raw); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := state.Cache.Get(filePath, key); !ok {
+		t.Error("expected the disabled file to be cached with the key of its whole content")
+	}
+}
+
+func TestIsText(t *testing.T) {
+	for mime, expected := range map[string]bool{
+		"text/plain":               true,
+		"application/octet-stream": true,
+		"application/json":         true,
+		"application/ld+json":      true,
+		"application/atom+xml":     true,
+		"application/jsonl":        false,
+		"image/png":                false,
+	} {
+		if isText(mime) != expected {
+			t.Errorf("isText(%s): expected %v, got %v", mime, expected, !expected)
+		}
+	}
+}
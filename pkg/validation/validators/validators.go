@@ -4,7 +4,6 @@ package validators
 import (
 	"errors"
 	"fmt"
-	"regexp"
 	"strings"
 	"unicode/utf8"
 
@@ -15,12 +14,11 @@ import (
 	// x-release-please-end
 )
 
+// the errors without details are created once, so the validators do not allocate for the lines with errors either
 var (
-	spaceRegexp              = regexp.MustCompile(`^( )*([^ \t]|$)`)
-	tabRegexp                = regexp.MustCompile("^(\t)*( \\* ?|[^ \t]|$)")
-	spacesAfterTabsRegexp    = regexp.MustCompile("(^(\t)*\\S)|(^(\t)+( )*\\S)|(^ \\S)")
-	trailingWhitespaceRegexp = regexp.MustCompile("^.*[ \t]+$")
-	finalNewlineRegexp       = regexp.MustCompile("(\n|\r|\r\n)$")
+	errTabsInsteadOfSpaces = errors.New("Wrong indent style found (tabs instead of spaces)")
+	errSpacesInsteadOfTabs = errors.New("Wrong indentation type (spaces instead of tabs)")
+	errTrailingWhitespace  = errors.New("Trailing whitespace")
 )
 
 // Indentation validates a files indentation
@@ -36,23 +34,20 @@ func Indentation(line string, indentStyle string, indentSize int, config config.
 }
 
 // Space validates if a line is indented correctly respecting the indentSize
-func Space(line string, indentSize int, config config.Config) error {
+func Space(line string, indentSize int, cfg config.Config) error {
 	if len(line) > 0 {
-		// match recurring spaces and everything except tab characters
-		matched := spaceRegexp.MatchString(line)
+		spaces := countLeading(line, ' ')
 
-		if !matched {
-			return fmt.Errorf("Wrong indent style found (tabs instead of spaces)")
+		// recurring spaces may not be followed by a tab character
+		if spaces < len(line) && line[spaces] == '\t' {
+			return errTabsInsteadOfSpaces
 		}
 
-		if !config.Disable.IndentSize && indentSize > 0 {
-			// match recurring spaces indentSize times - this can be recurring or never
-			// match either a space followed by a * and maybe a space (block-comments)
-			// or match everything despite a space or tab-character
-			regexpPattern := fmt.Sprintf("^( {%d})*( \\* ?|[^ \t]|$)", indentSize)
-			matched, _ := regexp.MatchString(regexpPattern, line)
-
-			if !matched {
+		if !cfg.Disable.IsDisabled(config.IndentSizeCheck) && indentSize > 0 {
+			// the spaces are recurring indentSize times - this can be recurring or never
+			// or have one more space followed by a * (block-comments)
+			rest := spaces % indentSize
+			if rest != 0 && (rest != 1 || spaces == len(line) || line[spaces] != '*') {
 				return fmt.Errorf("Wrong amount of left-padding spaces(want multiple of %d)", indentSize)
 			}
 		}
@@ -64,22 +59,26 @@ func Space(line string, indentSize int, config config.Config) error {
 // Tab validates if a line is indented with only tabs
 func Tab(line string, config config.Config) error {
 	if len(line) > 0 {
-		// match starting with one or more tabs followed by a non-whitespace char
-		// OR
-		// match starting with one or more tabs, followed by one space and followed by at least one non-whitespace character
-		// OR
-		// match starting with a space followed by at least one non-whitespace character
-
-		re := tabRegexp
+		tabs := countLeading(line, '\t')
 
+		var matched bool
 		if config.SpacesAfterTabs {
-			re = spacesAfterTabsRegexp
+			// starting with tabs followed by a non-whitespace character
+			// OR
+			// starting with one or more tabs, followed by spaces and a non-whitespace character
+			// OR
+			// starting with a space followed by a non-whitespace character
+			spaces := countLeading(line[tabs:], ' ')
+			matched = (tabs < len(line) && !isWhitespace(line[tabs])) ||
+				(tabs > 0 && tabs+spaces < len(line) && !isWhitespace(line[tabs+spaces])) ||
+				(len(line) > 1 && line[0] == ' ' && !isWhitespace(line[1]))
+		} else {
+			// starting with tabs, which may be followed by a space and a * (block-comments), but not by any other space
+			matched = tabs == len(line) || line[tabs] != ' ' || (tabs+1 < len(line) && line[tabs+1] == '*')
 		}
 
-		matched := re.MatchString(line)
-
 		if !matched {
-			return errors.New("Wrong indentation type (spaces instead of tabs)")
+			return errSpacesInsteadOfTabs
 		}
 
 	}
@@ -87,13 +86,31 @@ func Tab(line string, config config.Config) error {
 	return nil
 }
 
+// countLeading returns the number of times the character is repeated at the start of the line
+func countLeading(line string, character byte) int {
+	count := 0
+	for count < len(line) && line[count] == character {
+		count++
+	}
+	return count
+}
+
+// isWhitespace returns whether the character is whitespace like \s of a regular expression
+func isWhitespace(character byte) bool {
+	switch character {
+	case ' ', '\t', '\n', '\f', '\r':
+		return true
+	}
+	return false
+}
+
 // TrailingWhitespace validates if a line has trailing whitespace
 func TrailingWhitespace(line string, trimTrailingWhitespace bool) error {
-	if trimTrailingWhitespace {
-		matched := trailingWhitespaceRegexp.MatchString(line)
-
-		if matched {
-			return errors.New("Trailing whitespace")
+	if trimTrailingWhitespace && len(line) > 0 {
+		last := line[len(line)-1]
+		// like the content of a line without its end of line characters, there must be no line break in it
+		if (last == ' ' || last == '\t') && !strings.Contains(line, "\n") {
+			return errTrailingWhitespace
 		}
 	}
 
@@ -108,7 +125,7 @@ func FinalNewline(fileContent string, insertFinalNewline string, endOfLine strin
 			return errors.New("Wrong line endings or no final newline")
 		}
 	} else {
-		hasFinalNewline := finalNewlineRegexp.MatchString(fileContent)
+		hasFinalNewline := strings.HasSuffix(fileContent, "\n") || strings.HasSuffix(fileContent, "\r")
 
 		if insertFinalNewline == "false" && hasFinalNewline {
 			return errors.New("No final newline expected")
@@ -122,27 +139,48 @@ func FinalNewline(fileContent string, insertFinalNewline string, endOfLine strin
 	return nil
 }
 
+// EndOfLineCount is the number of each end of line character sequence in a file.
+// A \r\n is counted as CRLF as well as CR and LF, like when splitting the content by each of them.
+type EndOfLineCount struct {
+	LF   int
+	CR   int
+	CRLF int
+}
+
+// Add counts the end of line characters of a part of a file.
+// A \r\n must not be split between the parts.
+func (c *EndOfLineCount) Add(content string) {
+	c.LF += strings.Count(content, "\n")
+	cr := strings.Count(content, "\r")
+	c.CR += cr
+	// there is no \r\n to search for in the usual content without any \r
+	if cr > 0 {
+		c.CRLF += strings.Count(content, "\r\n")
+	}
+}
+
 // LineEnding validates if a file uses the correct line endings
 func LineEnding(fileContent string, endOfLine string) error {
-	if endOfLine != "" && endOfLine != "unset" {
-		expectedEolChar := utils.GetEolChar(endOfLine)
-		expectedEols := len(strings.Split(fileContent, expectedEolChar))
-		lfEols := len(strings.Split(fileContent, "\n"))
-		crEols := len(strings.Split(fileContent, "\r"))
-		crlfEols := len(strings.Split(fileContent, "\r\n"))
+	var count EndOfLineCount
+	count.Add(fileContent)
+	return LineEndingCount(count, endOfLine)
+}
 
+// LineEndingCount validates if a file uses the correct line endings by the number of its end of line characters
+func LineEndingCount(count EndOfLineCount, endOfLine string) error {
+	if endOfLine != "" && endOfLine != "unset" {
 		switch endOfLine {
 		case "lf":
-			if !(expectedEols == lfEols && crEols == 1 && crlfEols == 1) {
+			if !(count.CR == 0 && count.CRLF == 0) {
 				return errors.New("Not all lines have the correct end of line character")
 			}
 		case "cr":
-			if !(expectedEols == crEols && lfEols == 1 && crlfEols == 1) {
+			if !(count.LF == 0 && count.CRLF == 0) {
 				return errors.New("Not all lines have the correct end of line character")
 			}
 		case "crlf":
 			// A bit hacky because \r\n matches \r and \n
-			if !(expectedEols == crlfEols && lfEols == expectedEols && crEols == expectedEols) {
+			if !(count.LF == count.CRLF && count.CR == count.CRLF) {
 				return errors.New("Not all lines have the correct end of line character")
 			}
 		}
@@ -2,7 +2,12 @@ package validators
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 
 	// x-release-please-start-major
@@ -313,3 +318,169 @@ func TestCharsetLatin1IsNotUtf8(t *testing.T) {
 		t.Errorf(`Charset("latin1", "ISO-8859-1"): expected nil, got %v`, err)
 	}
 }
+
+// the validators used to match the lines with these regular expressions,
+// they are kept to check that the validators still behave the same and to compare their speed
+var (
+	spaceRegexp              = regexp.MustCompile(`^( )*([^ \t]|$)`)
+	tabRegexp                = regexp.MustCompile("^(\t)*( \\* ?|[^ \t]|$)")
+	spacesAfterTabsRegexp    = regexp.MustCompile("(^(\t)*\\S)|(^(\t)+( )*\\S)|(^ \\S)")
+	trailingWhitespaceRegexp = regexp.MustCompile("^.*[ \t]+$")
+)
+
+func regexpSpace(line string, indentSize int) bool {
+	if len(line) == 0 {
+		return true
+	}
+	if !spaceRegexp.MatchString(line) {
+		return false
+	}
+	matched, _ := regexp.MatchString(fmt.Sprintf("^( {%d})*( \\* ?|[^ \t]|$)", indentSize), line)
+	return matched
+}
+
+func regexpTab(line string, spacesAfterTabs bool) bool {
+	if len(line) == 0 {
+		return true
+	}
+	if spacesAfterTabs {
+		return spacesAfterTabsRegexp.MatchString(line)
+	}
+	return tabRegexp.MatchString(line)
+}
+
+func regexpEndOfLineCount(content string) EndOfLineCount {
+	return EndOfLineCount{
+		LF:   len(strings.Split(content, "\n")) - 1,
+		CR:   len(strings.Split(content, "\r")) - 1,
+		CRLF: len(strings.Split(content, "\r\n")) - 1,
+	}
+}
+
+// corpus returns the lines of the testfiles without their end of line characters
+func corpus(t testing.TB) []string {
+	t.Helper()
+	paths, err := filepath.Glob("../../../testfiles/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			lines = append(lines, strings.TrimSuffix(line, "\r"))
+		}
+	}
+	return lines
+}
+
+// combinations returns all lines of up to the length made up of the characters
+func combinations(characters string, length int) []string {
+	lines := []string{""}
+	previous := []string{""}
+	for range length {
+		var next []string
+		for _, line := range previous {
+			for _, character := range characters {
+				next = append(next, line+string(character))
+			}
+		}
+		lines = append(lines, next...)
+		previous = next
+	}
+	return lines
+}
+
+func TestValidatorsMatchRegexps(t *testing.T) {
+	// all short combinations of the characters which matter to the validators cover their edge cases
+	lines := append(corpus(t), combinations(" \t*x\u00e9\r\n\xff", 4)...)
+
+	for _, line := range lines {
+		for _, indentSize := range []int{1, 2, 3, 4} {
+			if actual := Space(line, indentSize, config.Config{}) == nil; actual != regexpSpace(line, indentSize) {
+				t.Errorf("Space(%q, %d): expected to be valid: %v, got: %v", line, indentSize, !actual, actual)
+			}
+		}
+		for _, spacesAfterTabs := range []bool{false, true} {
+			if actual := Tab(line, config.Config{SpacesAfterTabs: spacesAfterTabs}) == nil; actual != regexpTab(line, spacesAfterTabs) {
+				t.Errorf("Tab(%q, %v): expected to be valid: %v, got: %v", line, spacesAfterTabs, !actual, actual)
+			}
+		}
+		if actual := TrailingWhitespace(line, true) != nil; actual != trailingWhitespaceRegexp.MatchString(line) {
+			t.Errorf("TrailingWhitespace(%q): expected trailing whitespace: %v, got: %v", line, !actual, actual)
+		}
+		var count EndOfLineCount
+		count.Add(line)
+		if expected := regexpEndOfLineCount(line); count != expected {
+			t.Errorf("EndOfLineCount.Add(%q): expected: %+v, got: %+v", line, expected, count)
+		}
+	}
+}
+
+// BenchmarkValidators compares the validators with the regular expressions they used,
+// on every line of the testfiles
+func BenchmarkValidators(b *testing.B) {
+	lines := corpus(b)
+	content := strings.Join(lines, "\n")
+
+	benchmarks := []struct {
+		name     string
+		regexp   func(line string)
+		validate func(line string)
+	}{
+		{
+			"Space",
+			func(line string) { regexpSpace(line, 4) },
+			func(line string) { _ = Space(line, 4, config.Config{}) },
+		},
+		{
+			"Tab",
+			func(line string) { regexpTab(line, false) },
+			func(line string) { _ = Tab(line, config.Config{}) },
+		},
+		{
+			"SpacesAfterTabs",
+			func(line string) { regexpTab(line, true) },
+			func(line string) { _ = Tab(line, config.Config{SpacesAfterTabs: true}) },
+		},
+		{
+			"TrailingWhitespace",
+			func(line string) { trailingWhitespaceRegexp.MatchString(line) },
+			func(line string) { _ = TrailingWhitespace(line, true) },
+		},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name+"/regexp", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				for _, line := range lines {
+					bm.regexp(line)
+				}
+			}
+		})
+		b.Run(bm.name+"/scanner", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				for _, line := range lines {
+					bm.validate(line)
+				}
+			}
+		})
+	}
+
+	b.Run("LineEnding/split", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = LineEndingCount(regexpEndOfLineCount(content), "lf")
+		}
+	})
+	b.Run("LineEnding/count", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = LineEnding(content, "lf")
+		}
+	})
+}
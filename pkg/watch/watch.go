@@ -0,0 +1,354 @@
+// Package watch checks files again whenever they change
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	eccerror "github.com/editorconfig-checker/editorconfig-checker/v3/pkg/error"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/files"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/gitignore"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/run"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/validation"
+	// x-release-please-end
+)
+
+// DefaultInterval is the time between two polls of the files for changes
+const DefaultInterval = time.Second
+
+// editorconfigFileName is the name of the files whose changes affect all files in their directory
+const editorconfigFileName = ".editorconfig"
+
+// gitignoreFileName is the name of the files whose changes may change which files are found
+const gitignoreFileName = ".gitignore"
+
+// ignoreFileNames are the names of the files in the watched directories whose changes may change which files are found
+var ignoreFileNames = []string{gitignoreFileName, config.IgnoreFileName}
+
+// fileState is what a change of a file is detected by
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Snapshot holds the state of files by their absolute path
+type Snapshot map[string]fileState
+
+// Take returns the state of the files, files which do not exist are left out
+func Take(filePaths []string) Snapshot {
+	snapshot := make(Snapshot, len(filePaths))
+	for _, filePath := range filePaths {
+		fileInfo, err := os.Stat(filePath)
+		if err != nil {
+			continue
+		}
+		snapshot[absolutePath(filePath)] = fileState{modTime: fileInfo.ModTime(), size: fileInfo.Size()}
+	}
+	return snapshot
+}
+
+// Changes returns the files which were added or modified since the previous snapshot, and the ones which were removed
+func (s Snapshot) Changes(previous Snapshot) ([]string, []string) {
+	var changed, removed []string
+	for filePath, state := range s {
+		if previousState, ok := previous[filePath]; !ok || previousState != state {
+			changed = append(changed, filePath)
+		}
+	}
+	for filePath := range previous {
+		if _, ok := s[filePath]; !ok {
+			removed = append(removed, filePath)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(removed)
+	return changed, removed
+}
+
+// Affected returns the files which need to be checked again: the changed ones,
+// and all files in the directories of changed or removed .editorconfig files
+func Affected(filePaths []string, changed []string, removed []string) []string {
+	isChanged := make(map[string]bool, len(changed))
+	for _, filePath := range changed {
+		isChanged[filePath] = true
+	}
+
+	var directories []string
+	for _, filePath := range append(changed, removed...) {
+		if filepath.Base(filePath) == editorconfigFileName {
+			directories = append(directories, filepath.Dir(filePath))
+		}
+	}
+
+	var affected []string
+	for _, filePath := range filePaths {
+		absoluteFilePath := absolutePath(filePath)
+		if isChanged[absoluteFilePath] || isWithinAny(absoluteFilePath, directories) {
+			affected = append(affected, filePath)
+		}
+	}
+	return affected
+}
+
+// isWithinAny returns whether the file is inside of any of the directories
+func isWithinAny(filePath string, directories []string) bool {
+	for _, directory := range directories {
+		if strings.HasPrefix(filePath, strings.TrimSuffix(directory, string(filepath.Separator))+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// absolutePath returns the absolute path of a file, or the path itself if it cannot be determined
+func absolutePath(filePath string) string {
+	absoluteFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return filePath
+	}
+	return absoluteFilePath
+}
+
+// parentEditorconfigs returns the paths the .editorconfig files of the working directory
+// and its parent directories would have, as they apply to all files but are not checked themselves
+func parentEditorconfigs() []string {
+	directory, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+
+	var filePaths []string
+	for {
+		filePaths = append(filePaths, filepath.Join(directory, editorconfigFileName))
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			return filePaths
+		}
+		directory = parent
+	}
+}
+
+// isDiscoveryChange returns whether a change of the file may change which files are found,
+// like a file added to or removed from a watched directory, a changed .editorconfig, .gitignore or .ecignore,
+// or a change of the other discovery paths, like the config file
+func isDiscoveryChange(filePath string, discoveryPaths map[string]bool) bool {
+	switch filepath.Base(filePath) {
+	case editorconfigFileName, gitignoreFileName, config.IgnoreFileName:
+		return true
+	}
+	return discoveryPaths[filePath]
+}
+
+// watchedDirectories returns the absolute paths of the directories whose entries are watched:
+// the roots, the directories of the files up to their root, and the subdirectories of all of them,
+// so files added to a new directory are found as well
+func watchedDirectories(roots []string, filePaths []string) []string {
+	absoluteRoots := make([]string, 0, len(roots))
+	for _, root := range roots {
+		absoluteRoots = append(absoluteRoots, absolutePath(root))
+	}
+
+	isWatched := make(map[string]bool)
+	for _, root := range absoluteRoots {
+		isWatched[root] = true
+	}
+	for _, filePath := range filePaths {
+		directory := filepath.Dir(absolutePath(filePath))
+		for !isWatched[directory] {
+			isWatched[directory] = true
+			parent := filepath.Dir(directory)
+			if parent == directory || !isWithinAny(directory, absoluteRoots) {
+				break
+			}
+			directory = parent
+		}
+	}
+
+	directories := make([]string, 0, len(isWatched))
+	for directory := range isWatched {
+		directories = append(directories, directory)
+		entries, err := os.ReadDir(directory)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			subdirectory := filepath.Join(directory, entry.Name())
+			if entry.IsDir() && entry.Name() != ".git" && !isWatched[subdirectory] {
+				directories = append(directories, subdirectory)
+			}
+		}
+	}
+	sort.Strings(directories)
+	return directories
+}
+
+// roots returns the directories the files are searched in, the working directory if no paths are passed
+func roots(config config.Config) []string {
+	if len(config.PassedFiles) == 0 {
+		return []string{"."}
+	}
+	var directories []string
+	for _, passedFile := range config.PassedFiles {
+		if fileInfo, err := os.Stat(passedFile); err == nil && fileInfo.IsDir() {
+			directories = append(directories, passedFile)
+		}
+	}
+	return directories
+}
+
+// watched holds the files which are checked and everything polled for changes
+type watched struct {
+	filePaths []string
+	// discoveryPaths are the watched directories and the files besides the ignore files of the directories
+	// whose changes may change which files are found
+	discoveryPaths map[string]bool
+	// polled are the paths whose state is compared on every poll
+	polled []string
+}
+
+// discover finds the files which should be checked and what has to be polled to notice changes of them,
+// reading the .ecignore files again
+func discover(ctx context.Context, config config.Config, state *run.State) (watched, error) {
+	state.ReloadIgnoreFiles()
+	discovered, err := files.DiscoverAll(ctx, config, state)
+	if err != nil {
+		return watched{}, err
+	}
+
+	w := watched{filePaths: make([]string, 0, len(discovered)), discoveryPaths: make(map[string]bool)}
+	for _, file := range discovered {
+		w.filePaths = append(w.filePaths, file.Path)
+	}
+	w.polled = append(w.polled, w.filePaths...)
+	w.polled = append(w.polled, parentEditorconfigs()...)
+	for _, directory := range watchedDirectories(roots(config), w.filePaths) {
+		w.discoveryPaths[directory] = true
+		w.polled = append(w.polled, directory, filepath.Join(directory, editorconfigFileName))
+		for _, ignoreFileName := range ignoreFileNames {
+			w.polled = append(w.polled, filepath.Join(directory, ignoreFileName))
+		}
+	}
+
+	// the config file and the info/exclude files of the repositories apply to all files
+	var otherPaths []string
+	if config.Path != "" {
+		otherPaths = append(otherPaths, config.Path)
+	}
+	for _, root := range roots(config) {
+		if excludeFile, ok := gitignore.ExcludeFile(root); ok {
+			otherPaths = append(otherPaths, excludeFile)
+		}
+	}
+	for _, otherPath := range otherPaths {
+		w.discoveryPaths[absolutePath(otherPath)] = true
+		w.polled = append(w.polled, otherPath)
+	}
+	return w, nil
+}
+
+// Run checks the files, then polls them for changes every interval and checks the affected files again,
+// until the context is done. The files are found like without watching, honoring the same excludes.
+// Only the state of the known files and their directories is polled, the files are searched again
+// only if a directory, an .editorconfig, an ignore file or the config file changed. Files which vanished are not checked anymore.
+// If the config file changed, all files are checked again with the config returned by reload, unless reload is nil.
+// After every check, report is called with the errors of all files.
+func Run(ctx context.Context, config config.Config, state *run.State, interval time.Duration, reload func() (config.Config, error), report func(errors []eccerror.ValidationErrors)) error {
+	results := make(map[string][]eccerror.ValidationError)
+	var snapshot Snapshot
+	var current watched
+	for {
+		if snapshot == nil {
+			var err error
+			if current, err = discover(ctx, config, state); err != nil {
+				return stopped(ctx, err)
+			}
+		}
+
+		latest := Take(current.polled)
+		changed, removed := latest.Changes(snapshot)
+		checkAll := snapshot == nil
+		if snapshot != nil && slices.ContainsFunc(append(changed, removed...), func(filePath string) bool {
+			return isDiscoveryChange(filePath, current.discoveryPaths)
+		}) {
+			if reload != nil && config.Path != "" && slices.Contains(append(changed, removed...), absolutePath(config.Path)) {
+				if reloaded, err := reload(); err != nil {
+					config.Logger.Error("%v", err.Error())
+				} else {
+					config = reloaded
+					state.ReloadEditorconfigs(&config)
+					checkAll = true
+				}
+			}
+			var err error
+			if current, err = discover(ctx, config, state); err != nil {
+				return stopped(ctx, err)
+			}
+			latest = Take(current.polled)
+			changed, removed = latest.Changes(snapshot)
+		}
+
+		if snapshot == nil || len(changed) != 0 || len(removed) != 0 {
+			for _, filePath := range append(changed, removed...) {
+				if filepath.Base(filePath) == editorconfigFileName {
+					state.ReloadEditorconfigs(&config)
+					break
+				}
+			}
+
+			// the files which vanished since they were found are not checked
+			var filePaths []string
+			for _, filePath := range current.filePaths {
+				if _, ok := latest[absolutePath(filePath)]; ok {
+					filePaths = append(filePaths, filePath)
+				}
+			}
+
+			affected := filePaths
+			if !checkAll {
+				affected = Affected(filePaths, changed, removed)
+			}
+			validationErrors, err := validation.ProcessValidationContext(ctx, affected, config, state)
+			if err != nil {
+				// stopped while checking
+				return nil
+			}
+			for _, fileErrors := range validationErrors {
+				results[fileErrors.FilePath] = fileErrors.Errors
+			}
+			if state.Cache != nil {
+				if err := state.Cache.Save(); err != nil {
+					config.Logger.Warning("Could not save the cache: %v", err.Error())
+				}
+			}
+
+			// only the files which are still to be checked are reported
+			errors := make([]eccerror.ValidationErrors, 0, len(filePaths))
+			for _, filePath := range filePaths {
+				errors = append(errors, eccerror.ValidationErrors{FilePath: filePath, Errors: results[filePath]})
+			}
+			report(errors)
+		}
+		snapshot = latest
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// stopped returns the error of searching the files, unless it is because the context is done
+func stopped(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,210 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	// x-release-please-start-major
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/config"
+	eccerror "github.com/editorconfig-checker/editorconfig-checker/v3/pkg/error"
+	"github.com/editorconfig-checker/editorconfig-checker/v3/pkg/run"
+	// x-release-please-end
+)
+
+func writeFile(t *testing.T, name string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChanges(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFile(t, "kept.txt", "kept\n")
+	writeFile(t, "modified.txt", "before\n")
+	writeFile(t, "removed.txt", "removed\n")
+	previous := Take([]string{"kept.txt", "modified.txt", "removed.txt", "missing.txt"})
+
+	writeFile(t, "modified.txt", "after the change\n")
+	writeFile(t, "added.txt", "added\n")
+	if err := os.Remove("removed.txt"); err != nil {
+		t.Fatal(err)
+	}
+	changed, removed := Take([]string{"kept.txt", "modified.txt", "added.txt"}).Changes(previous)
+
+	if expected := []string{absolutePath("added.txt"), absolutePath("modified.txt")}; !reflect.DeepEqual(changed, expected) {
+		t.Errorf("Changes: expected changed %v, got %v", expected, changed)
+	}
+	if expected := []string{absolutePath("removed.txt")}; !reflect.DeepEqual(removed, expected) {
+		t.Errorf("Changes: expected removed %v, got %v", expected, removed)
+	}
+}
+
+func TestAffected(t *testing.T) {
+	t.Chdir(t.TempDir())
+	filePaths := []string{"a.txt", "sub/b.txt", "sub/deeper/c.txt", "subdirectory/d.txt"}
+
+	affected := Affected(filePaths, []string{absolutePath("a.txt")}, nil)
+	if expected := []string{"a.txt"}; !reflect.DeepEqual(affected, expected) {
+		t.Errorf("Affected(file): expected %v, got %v", expected, affected)
+	}
+
+	// all files below a changed .editorconfig are affected
+	affected = Affected(filePaths, nil, []string{absolutePath("sub/.editorconfig")})
+	if expected := []string{"sub/b.txt", "sub/deeper/c.txt"}; !reflect.DeepEqual(affected, expected) {
+		t.Errorf("Affected(.editorconfig): expected %v, got %v", expected, affected)
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFile(t, ".editorconfig", "root = true\n\n[*]\ntrim_trailing_whitespace = true\n")
+	writeFile(t, "file.txt", "valid\n")
+	writeFile(t, "excluded.log", "excluded \n")
+
+	configuration := config.NewConfig(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reports := make(chan []eccerror.ValidationErrors)
+	done := make(chan error)
+	go func() {
+		done <- Run(ctx, *configuration, run.New(), 10*time.Millisecond, nil, func(errors []eccerror.ValidationErrors) {
+			reports <- errors
+		})
+	}()
+
+	// the excludes apply like without watching
+	if count := eccerror.GetErrorCount(<-reports); count != 0 {
+		t.Errorf("expected no errors at first, got %d", count)
+	}
+
+	writeFile(t, "file.txt", "trailing whitespace \n")
+	if count := eccerror.GetErrorCount(<-reports); count != 1 {
+		t.Errorf("expected an error after the file changed, got %d", count)
+	}
+
+	// a changed .editorconfig checks the files it applies to again
+	writeFile(t, ".editorconfig", "root = true\n\n[*]\ntrim_trailing_whitespace = false\n")
+	if count := eccerror.GetErrorCount(<-reports); count != 0 {
+		t.Errorf("expected no errors after the .editorconfig changed, got %d", count)
+	}
+
+	// new files are found once their directory changes
+	writeFile(t, "new/added.txt", "trailing whitespace \n")
+	writeFile(t, ".editorconfig", "root = true\n\n[*]\ntrim_trailing_whitespace = true\n")
+	if count := eccerror.GetErrorCount(<-reports); count != 2 {
+		t.Errorf("expected 2 errors after a file was added, got %d", count)
+	}
+
+	// vanished files are not checked anymore
+	if err := os.Remove("file.txt"); err != nil {
+		t.Fatal(err)
+	}
+	errors := <-reports
+	if count := eccerror.GetErrorCount(errors); count != 1 {
+		t.Errorf("expected 1 error after a file was removed, got %d", count)
+	}
+	for _, fileErrors := range errors {
+		if absolutePath(fileErrors.FilePath) == absolutePath("file.txt") {
+			t.Errorf("expected the removed file not to be reported, got %v", errors)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRunDiscoveryFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if output, err := exec.Command("git", "init", "--quiet").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, output)
+	}
+	writeFile(t, ".editorconfig", "root = true\n\n[*]\ntrim_trailing_whitespace = true\n")
+	writeFile(t, "a.txt", "trailing whitespace \n")
+	writeFile(t, "b.txt", "trailing whitespace \n")
+	writeFile(t, "c.txt", "trailing whitespace \n")
+
+	configuration := config.NewConfig([]string{".editorconfig-checker.json"})
+	reload := func() (config.Config, error) {
+		reloaded := config.NewConfig([]string{configuration.Path})
+		err := reloaded.Parse()
+		return *reloaded, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reports := make(chan []eccerror.ValidationErrors)
+	done := make(chan error)
+	go func() {
+		done <- Run(ctx, *configuration, run.New(), 10*time.Millisecond, reload, func(errors []eccerror.ValidationErrors) {
+			reports <- errors
+		})
+	}()
+
+	if count := eccerror.GetErrorCount(<-reports); count != 3 {
+		t.Errorf("expected 3 errors at first, got %d", count)
+	}
+
+	// the files excluded by a changed .ecignore are not checked anymore
+	writeFile(t, config.IgnoreFileName, "a.txt\n")
+	if count := eccerror.GetErrorCount(<-reports); count != 2 {
+		t.Errorf("expected 2 errors after the .ecignore changed, got %d", count)
+	}
+
+	// so are the ones excluded by a changed info/exclude file of the repository
+	writeFile(t, filepath.Join(".git", "info", "exclude"), "b.txt\n")
+	if count := eccerror.GetErrorCount(<-reports); count != 1 {
+		t.Errorf("expected 1 error after the info/exclude file changed, got %d", count)
+	}
+
+	// a changed config file applies to all files
+	writeFile(t, ".editorconfig-checker.json", `{"Disable": {"TrimTrailingWhitespace": true}}`)
+	if count := eccerror.GetErrorCount(<-reports); count != 0 {
+		t.Errorf("expected no errors after the config file changed, got %d", count)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWatchedDirectories(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFile(t, "a.txt", "a\n")
+	writeFile(t, "sub/deeper/b.txt", "b\n")
+	writeFile(t, "empty/.keep", "")
+	writeFile(t, "empty/nested/.keep", "")
+	writeFile(t, ".git/HEAD", "")
+
+	// the directories of the files, their parents up to the root and the subdirectories of all of them are watched
+	directories := watchedDirectories([]string{"."}, []string{"a.txt", "sub/deeper/b.txt"})
+	var expected []string
+	for _, directory := range []string{".", "empty", "sub", "sub/deeper"} {
+		expected = append(expected, absolutePath(directory))
+	}
+	if !reflect.DeepEqual(directories, expected) {
+		t.Errorf("watchedDirectories: expected %v, got %v", expected, directories)
+	}
+
+	if !isDiscoveryChange(absolutePath("sub"), map[string]bool{absolutePath("sub"): true}) || !isDiscoveryChange(absolutePath("x/.gitignore"), nil) {
+		t.Error("isDiscoveryChange: expected a changed directory and .gitignore to search the files again")
+	}
+	if !isDiscoveryChange(absolutePath("x/.ecignore"), nil) || !isDiscoveryChange(absolutePath("config.json"), map[string]bool{absolutePath("config.json"): true}) {
+		t.Error("isDiscoveryChange: expected a changed .ecignore and config file to search the files again")
+	}
+	if isDiscoveryChange(absolutePath("a.txt"), map[string]bool{absolutePath("sub"): true}) {
+		t.Error("isDiscoveryChange: expected a changed file not to search the files again")
+	}
+}